@@ -0,0 +1,295 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/at-ishikawa/kubectl-fzf/internal/command"
+)
+
+type commonOptions struct {
+	namespace     string
+	allNamespaces bool
+	previewFormat string
+	selectOptions command.SelectOptions
+	backend       string
+	kubeContext   string
+	// finder is the name of the finder used by all commands including the selection of the kind of resources
+	finder string
+	// watchInterval is 0 unless --watch is set
+	watchInterval time.Duration
+	// getOptions are options of kubectl get to list objects
+	getOptions  map[string]string
+	config      *command.Config
+	logsPreview command.LogsPreviewOptions
+}
+
+func getCommonOptions(cmd *cobra.Command) (*commonOptions, error) {
+	flags := cmd.Flags()
+	namespace, err := flags.GetString("namespace")
+	if err != nil {
+		return nil, err
+	}
+	allNamespaces, err := flags.GetBool("all-namespaces")
+	if err != nil {
+		return nil, err
+	}
+	previewFormat, err := flags.GetString("preview-format")
+	if err != nil {
+		return nil, err
+	}
+	fzfQuery, err := flags.GetString("query")
+	if err != nil {
+		return nil, err
+	}
+	filterQuery, err := flags.GetString("filter")
+	if err != nil {
+		return nil, err
+	}
+	selectOne, err := flags.GetBool("select-1")
+	if err != nil {
+		return nil, err
+	}
+	exitZero, err := flags.GetBool("exit-0")
+	if err != nil {
+		return nil, err
+	}
+	selectOptions := command.SelectOptions{
+		Query:     fzfQuery,
+		SelectOne: selectOne,
+		ExitZero:  exitZero,
+	}
+	if flags.Changed("filter") {
+		selectOptions.Query = filterQuery
+		selectOptions.Filter = true
+	}
+	backend, err := flags.GetString("backend")
+	if err != nil {
+		return nil, err
+	}
+	kubeContext, err := flags.GetString("context")
+	if err != nil {
+		return nil, err
+	}
+	finder, err := flags.GetString("finder")
+	if err != nil {
+		return nil, err
+	}
+	config, err := loadConfig(cmd)
+	if err != nil {
+		return nil, err
+	}
+	logsTail, err := flags.GetInt("logs-tail")
+	if err != nil {
+		return nil, err
+	}
+	logsSince, err := flags.GetString("logs-since")
+	if err != nil {
+		return nil, err
+	}
+	logsPrevious, err := flags.GetBool("logs-previous")
+	if err != nil {
+		return nil, err
+	}
+	watch, err := flags.GetBool("watch")
+	if err != nil {
+		return nil, err
+	}
+	getOptions := map[string]string{}
+	selector, err := flags.GetString("selector")
+	if err != nil {
+		return nil, err
+	}
+	if selector != "" {
+		getOptions["--selector"] = selector
+	}
+	fieldSelector, err := flags.GetString("field-selector")
+	if err != nil {
+		return nil, err
+	}
+	if fieldSelector != "" {
+		getOptions["--field-selector"] = fieldSelector
+	}
+	showLabels, err := flags.GetBool("show-labels")
+	if err != nil {
+		return nil, err
+	}
+	if showLabels {
+		getOptions["--show-labels"] = "true"
+	}
+	var watchInterval time.Duration
+	if watch {
+		watchInterval, err = flags.GetDuration("watch-interval")
+		if err != nil {
+			return nil, err
+		}
+		if watchInterval <= 0 {
+			return nil, fmt.Errorf("--watch-interval must be positive: %s", watchInterval)
+		}
+	}
+	return &commonOptions{
+		backend:       backend,
+		kubeContext:   kubeContext,
+		finder:        finder,
+		namespace:     namespace,
+		allNamespaces: allNamespaces,
+		previewFormat: previewFormat,
+		selectOptions: selectOptions,
+		watchInterval: watchInterval,
+		getOptions:    getOptions,
+		config:        config,
+		logsPreview: command.LogsPreviewOptions{
+			Tail:     logsTail,
+			Since:    logsSince,
+			Previous: logsPrevious,
+		},
+	}, nil
+}
+
+// loadConfig loads the config file of --config, KUBECTL_FZF_CONFIG, or the default path.
+// The file on the default path is optional.
+func loadConfig(cmd *cobra.Command) (*command.Config, error) {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		// kubectl-fzf run by preview commands uses the same config file
+		if err := os.Setenv(command.EnvNameConfig, path); err != nil {
+			return nil, err
+		}
+	}
+	if path == "" {
+		path = os.Getenv(command.EnvNameConfig)
+	}
+	if path != "" {
+		return command.LoadConfig(path, true)
+	}
+	path, err = command.DefaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return command.LoadConfig(path, false)
+}
+
+// getResource returns the 1st argument with aliases resolved, or the resource selected on fzf if it's omitted.
+// It returns an empty string if fzf is canceled.
+func getResource(args []string, opts *commonOptions) (string, error) {
+	if len(args) > 0 {
+		return opts.config.ResolveAlias(args[0]), nil
+	}
+	cli, err := command.NewResourceCli(opts.kubeContext, opts.finder, opts.config)
+	if err != nil {
+		return "", err
+	}
+	return cli.SelectResource(context.Background(), os.Stderr)
+}
+
+// getCliOptions returns options of the cli to select objects from common options
+func (opts *commonOptions) getCliOptions() command.GetOptions {
+	return command.GetOptions{
+		PreviewFormat:  opts.previewFormat,
+		SelectOptions:  opts.selectOptions,
+		WatchInterval:  opts.watchInterval,
+		KubectlOptions: opts.getOptions,
+		Config:         opts.config,
+		LogsPreview:    &opts.logsPreview,
+		Finder:         opts.finder,
+	}
+}
+
+// useResourceConfig applies defaults of the config for the resource, unless they're overridden by flags
+func (opts *commonOptions) useResourceConfig(cmd *cobra.Command, resource string) {
+	flags := cmd.Flags()
+	if previewFormat := opts.config.ResourcePreviewFormat(resource); previewFormat != "" && !flags.Changed("preview-format") {
+		opts.previewFormat = previewFormat
+	}
+	// Labels cannot be shown with custom columns
+	if _, ok := opts.getOptions["--show-labels"]; ok {
+		return
+	}
+	for k, v := range opts.config.ResourceGetOptions(resource, opts.allNamespaces) {
+		opts.getOptions[k] = v
+	}
+}
+
+func newActionCommand(action string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               fmt.Sprintf("%s [resource]", action),
+		Short:             fmt.Sprintf("kubectl %s [resource] command for objects selected with fzf", action),
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: command.CompleteResources,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := getCommonOptions(cmd)
+			if err != nil {
+				return err
+			}
+			kubectlOptions := map[string]string{}
+			var commandArgs []string
+			if action == "exec" {
+				args, commandArgs = splitExecArgs(cmd, args)
+			}
+			if action == "logs" {
+				follow, err := cmd.Flags().GetBool("follow")
+				if err != nil {
+					return err
+				}
+				if follow {
+					kubectlOptions["--follow"] = "true"
+				}
+			}
+
+			resource, err := getResource(args, opts)
+			if err != nil {
+				return err
+			}
+			if resource == "" {
+				return nil
+			}
+			opts.useResourceConfig(cmd, resource)
+			kubectl, err := command.NewKubectl(resource, opts.namespace, opts.allNamespaces, opts.kubeContext)
+			if err != nil {
+				return err
+			}
+			backend, err := command.NewBackend(opts.backend, kubectl)
+			if err != nil {
+				return err
+			}
+			getOptions := opts.getCliOptions()
+			getOptions.SelectContainers = command.ActionSelectsContainers(backend, action)
+			getCli, err := command.NewGetCli(backend, getOptions)
+			if err != nil {
+				return err
+			}
+			cli, err := command.NewActionCli(backend, getCli, action, kubectlOptions, commandArgs)
+			if err != nil {
+				return err
+			}
+			return cli.Run(context.Background(), os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+	switch action {
+	case "logs":
+		cmd.Flags().BoolP("follow", "f", false, "Specify if the logs should be streamed")
+	case "exec":
+		cmd.Use = "exec [resource] [-- COMMAND [args...]]"
+		cmd.Args = func(cmd *cobra.Command, args []string) error {
+			resourceArgs, _ := splitExecArgs(cmd, args)
+			return cobra.MaximumNArgs(1)(cmd, resourceArgs)
+		}
+	}
+	return cmd
+}
+
+// splitExecArgs splits arguments of exec into the optional resource before -- and the command after it
+func splitExecArgs(cmd *cobra.Command, args []string) ([]string, []string) {
+	dash := cmd.ArgsLenAtDash()
+	if dash < 0 {
+		return args, nil
+	}
+	return args[:dash], args[dash:]
+}
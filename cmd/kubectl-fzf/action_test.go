@@ -0,0 +1,65 @@
+package main
+
+import (
+	"io"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitExecArgs(t *testing.T) {
+	testCases := []struct {
+		name             string
+		args             []string
+		wantResourceArgs []string
+		wantCommandArgs  []string
+		wantErr          bool
+	}{
+		{
+			name:             "no arguments to select the kind on fzf",
+			args:             []string{},
+			wantResourceArgs: []string{},
+		},
+		{
+			name:             "command without the resource",
+			args:             []string{"--", "bash"},
+			wantResourceArgs: []string{},
+			wantCommandArgs:  []string{"bash"},
+		},
+		{
+			name:             "resource and command",
+			args:             []string{"pods", "--", "bash", "-l"},
+			wantResourceArgs: []string{"pods"},
+			wantCommandArgs:  []string{"bash", "-l"},
+		},
+		{
+			name:             "resource without the command",
+			args:             []string{"pods"},
+			wantResourceArgs: []string{"pods"},
+		},
+		{
+			name:    "command without --",
+			args:    []string{"pods", "bash"},
+			wantErr: true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotResourceArgs, gotCommandArgs []string
+			cmd := newActionCommand("exec")
+			cmd.RunE = func(cmd *cobra.Command, args []string) error {
+				gotResourceArgs, gotCommandArgs = splitExecArgs(cmd, args)
+				return nil
+			}
+			cmd.SetArgs(tc.args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			gotErr := cmd.Execute()
+			assert.Equal(t, tc.wantErr, gotErr != nil)
+			assert.Equal(t, tc.wantResourceArgs, gotResourceArgs)
+			assert.Equal(t, tc.wantCommandArgs, gotCommandArgs)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/at-ishikawa/kubectl-fzf/internal/command"
+)
+
+func newCompleteCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "complete",
+		Short: "Select objects for the kubectl command line being typed, and output them to insert at the cursor",
+		Long: `Select objects for the kubectl command line being typed, and output them to insert at the cursor.
+The resource, the namespace and the context are inferred from the command line before the cursor,
+like pods for "kubectl logs " or services in the namespace for "kubectl -n foo describe svc ".
+A partial name of an object before the cursor, like "ng" of "kubectl logs ng", is the query of fzf and replaced by the selected objects.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := getCommonOptions(cmd)
+			if err != nil {
+				return err
+			}
+			line, err := cmd.Flags().GetString("line")
+			if err != nil {
+				return err
+			}
+			target, err := command.ParseCommandLine(line)
+			if err != nil {
+				return err
+			}
+			if target.Namespace != "" {
+				opts.namespace = target.Namespace
+			}
+			if target.AllNamespaces {
+				opts.allNamespaces = true
+			}
+			if target.Context != "" {
+				opts.kubeContext = target.Context
+			}
+
+			resource := opts.config.ResolveAlias(target.Resource)
+			if resource == "" {
+				cli, err := command.NewResourceCli(opts.kubeContext, opts.finder, opts.config)
+				if err != nil {
+					return err
+				}
+				resource, err = cli.SelectResource(context.Background(), os.Stderr)
+				if err != nil {
+					return err
+				}
+			}
+			opts.useResourceConfig(cmd, resource)
+			kubectl, err := command.NewKubectl(resource, opts.namespace, opts.allNamespaces, opts.kubeContext)
+			if err != nil {
+				return err
+			}
+			backend, err := command.NewBackend(opts.backend, kubectl)
+			if err != nil {
+				return err
+			}
+			getOptions := opts.getCliOptions()
+			getOptions.OutputFormat = target.Output
+			if target.Query != "" {
+				getOptions.SelectOptions.Query = target.Query
+			}
+			getCli, err := command.NewGetCli(backend, getOptions)
+			if err != nil {
+				return err
+			}
+			return command.NewCompleteCli(line, target.Word, getCli).Run(context.Background(), os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+	cmd.Flags().String("line", "", "The command line before the cursor")
+	return cmd
+}
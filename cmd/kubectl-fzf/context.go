@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/at-ishikawa/kubectl-fzf/internal/command"
+)
+
+func newContextCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ctx",
+		Short: "Select a context of kubeconfig with fzf, and output or switch to it",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := getCommonOptions(cmd)
+			if err != nil {
+				return err
+			}
+			switchContext, err := cmd.Flags().GetBool("switch")
+			if err != nil {
+				return err
+			}
+			cli, err := command.NewContextCli(opts.selectOptions.Query, switchContext, opts.finder, opts.config)
+			if err != nil {
+				return err
+			}
+			return cli.Run(context.Background(), os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+	cmd.Flags().BoolP("switch", "s", false, "Switch the current context to the selected one")
+	return cmd
+}
+
+func newNamespaceCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ns",
+		Short: "Select namespaces with fzf, and output them or switch the namespace of the context to one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts, err := getCommonOptions(cmd)
+			if err != nil {
+				return err
+			}
+			switchNamespace, err := cmd.Flags().GetBool("switch")
+			if err != nil {
+				return err
+			}
+
+			// Namespaces are not namespaced, so --namespace and --all-namespaces are ignored
+			kubectl, err := command.NewKubectl("namespaces", "", false, opts.kubeContext)
+			if err != nil {
+				return err
+			}
+			backend, err := command.NewBackend(opts.backend, kubectl)
+			if err != nil {
+				return err
+			}
+			getCli, err := command.NewGetCli(backend, opts.getCliOptions())
+			if err != nil {
+				return err
+			}
+			cli, err := command.NewNamespaceCli(backend, getCli, switchNamespace)
+			if err != nil {
+				return err
+			}
+			return cli.Run(context.Background(), os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+	cmd.Flags().BoolP("switch", "s", false, "Switch the namespace of the current context, or the one of --context, to the selected one")
+	return cmd
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/at-ishikawa/kubectl-fzf/internal/command"
+)
+
+func newInitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init [bash|zsh|fish]",
+		Short: "Output key bindings of the shell to insert objects selected with fzf at the cursor",
+		Long: `Output key bindings of the shell to insert objects selected with fzf at the cursor.
+Add the next line to the config of the shell.
+  bash: eval "$(kubectl fzf init bash)"
+  zsh:  eval "$(kubectl fzf init zsh)"
+  fish: kubectl fzf init fish | source`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{command.ShellBash, command.ShellZsh, command.ShellFish},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			flags := cmd.Flags()
+			prefix := config.ShellPrefix()
+			if flags.Changed("prefix") {
+				prefix, err = flags.GetString("prefix")
+				if err != nil {
+					return err
+				}
+			}
+			bindings := config.ShellBindings()
+			bindArgs, err := flags.GetStringArray("bind")
+			if err != nil {
+				return err
+			}
+			if len(bindArgs) > 0 {
+				bindings = make(map[string]string, len(bindArgs))
+				for _, arg := range bindArgs {
+					i := strings.Index(arg, ":")
+					if i < 0 {
+						return fmt.Errorf("--bind must be like ctrl-p:pods: %q", arg)
+					}
+					bindings[arg[:i]] = arg[i+1:]
+				}
+			}
+			completeKey := config.ShellCompleteKey()
+			if flags.Changed("complete-key") {
+				completeKey, err = flags.GetString("complete-key")
+				if err != nil {
+					return err
+				}
+			}
+			cli, err := command.NewInitCli(args[0], prefix, bindings, completeKey)
+			if err != nil {
+				return err
+			}
+			return cli.Run(context.Background(), os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+	flags := cmd.Flags()
+	flags.String("prefix", command.DefaultShellPrefix, "The prefix key of key bindings, like ctrl-x,ctrl-k. No prefix key is used if it's empty")
+	flags.StringArray("bind", nil, "The key after the prefix and the resource selected by it, like ctrl-p:pods, which replace the default ones. The kind of resources is selected at first for an empty resource like ctrl-k:")
+	flags.String("complete-key", command.DefaultShellCompleteKey, "The key after the prefix to select objects for the command line being typed by kubectl fzf complete. It's disabled if it's empty")
+	return cmd
+}
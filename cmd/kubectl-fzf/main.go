@@ -2,43 +2,98 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"github.com/at-ishikawa/kubectl-fzf/internal/command"
 )
 
+// Exit codes of the command
+const (
+	exitCodeNoMatch        = 1
+	exitCodeError          = 2
+	exitCodeKubectl        = 3
+	exitCodeFinderNotFound = 4
+	exitCodeCanceled       = 130
+)
+
+// exitCode returns the exit code for the error
+func exitCode(err error) int {
+	var kubectlErr *command.KubectlError
+	var exitErr *command.ExitError
+	switch {
+	case errors.As(err, &exitErr):
+		return exitErr.Code
+	case errors.Is(err, command.ErrCanceled):
+		return exitCodeCanceled
+	case errors.Is(err, command.ErrNoMatch), errors.Is(err, command.ErrNoObjects):
+		return exitCodeNoMatch
+	case errors.Is(err, command.ErrFinderNotFound):
+		return exitCodeFinderNotFound
+	case errors.Is(err, command.ErrNamespaceNotFound), errors.Is(err, command.ErrUnknownResource), errors.Is(err, command.ErrForbidden),
+		errors.As(err, &kubectlErr):
+		return exitCodeKubectl
+	}
+	return exitCodeError
+}
+
 func main() {
 	cli := cobra.Command{
-		Use:           "kubectl-fzf [resource]",
-		Short:         "kubectl get [resource] command with fzf",
-		SilenceErrors: true,
-		SilenceUsage:  true,
-		Args:          cobra.MinimumNArgs(1),
+		Use:               "kubectl-fzf [resource]",
+		Long:              "kubectl get [resource] command with fzf.\nIf the resource is omitted, it's selected on fzf from kubectl api-resources.",
+		Short:             "kubectl get [resource] command with fzf",
+		SilenceErrors:     true,
+		SilenceUsage:      true,
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: command.CompleteResources,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			resource := args[0]
+			opts, err := getCommonOptions(cmd)
+			if err != nil {
+				return err
+			}
+			resource, err := getResource(args, opts)
+			if err != nil {
+				return err
+			}
+			if resource == "" {
+				return nil
+			}
+			opts.useResourceConfig(cmd, resource)
+			kubectl, err := command.NewKubectl(resource, opts.namespace, opts.allNamespaces, opts.kubeContext)
+			if err != nil {
+				return err
+			}
+			backend, err := command.NewBackend(opts.backend, kubectl)
+			if err != nil {
+				return err
+			}
 			flags := cmd.Flags()
-			namespace, err := flags.GetString("namespace")
+			getOptions := opts.getCliOptions()
+			getOptions.OutputFormat, err = flags.GetString("output")
 			if err != nil {
 				return err
 			}
-			previewFormat, err := flags.GetString("preview-format")
+			if !flags.Changed("output") {
+				getOptions.OutputFormat = opts.config.ResourceOutputFormat(resource)
+			}
+			getOptions.NulDelimited, err = flags.GetBool("null")
 			if err != nil {
 				return err
 			}
-			fzfQuery, err := flags.GetString("query")
+			getOptions.SelectContainers, err = flags.GetBool("select-container")
 			if err != nil {
 				return err
 			}
-
-			kubectl, err := command.NewKubectl(resource, namespace)
+			getOptions.Navigate, err = flags.GetBool("navigate")
 			if err != nil {
 				return err
 			}
-			cli, err := command.NewGetCli(kubectl, previewFormat, fzfQuery)
+			cli, err := command.NewGetCli(backend, getOptions)
 			if err != nil {
 				return err
 			}
@@ -48,12 +103,54 @@ func main() {
 			return nil
 		},
 	}
-	commonFlags := cli.Flags()
+	commonFlags := cli.PersistentFlags()
 	commonFlags.StringP("query", "q", "", "Start the fzf with this query")
+	commonFlags.String("filter", "", "Select all objects matching this query without the interaction, like fzf --filter. It fails if no objects match")
+	commonFlags.BoolP("select-1", "1", false, "Select the object without the interaction if only one object matches the query. With --filter, it fails if multiple objects match")
+	commonFlags.Bool("exit-0", false, "Exit with an error without the interaction if no objects match the query")
 	commonFlags.StringP("namespace", "n", "", "Kubernetes namespace")
-	commonFlags.StringP("preview-format", "p", "describe", "The format of preview")
+	commonFlags.String("context", "", "The name of the kubeconfig context to use")
+	commonFlags.BoolP("all-namespaces", "A", false, "List objects across all namespaces and output them as namespace/name")
+	commonFlags.String("backend", command.BackendKubectl, "The backend to list objects when the finder starts. Previews, reloads and actions still run a kubectl process each time. One of: kubectl|client-go")
+	commonFlags.StringP("selector", "l", "", "Selector (label query) to filter objects on, like app=payments")
+	commonFlags.String("field-selector", "", "Selector (field query) to filter objects on, like status.phase=Failed")
+	commonFlags.Bool("show-labels", false, "Show labels of objects as the last column")
+	commonFlags.BoolP("watch", "w", false, "Reload objects on fzf every --watch-interval. They can also be reloaded by ctrl-r")
+	commonFlags.Duration("watch-interval", 2*time.Second, "The interval to reload objects with --watch")
+	commonFlags.String("finder", command.FinderAuto, "The fuzzy finder. One of: auto|fzf|builtin. auto uses fzf if it's in PATH, or the builtin finder otherwise")
+	commonFlags.String("config", "", "The path of the config file. KUBECTL_FZF_CONFIG is used if it's omitted, or $XDG_CONFIG_HOME/kubectl-fzf/config.yaml by default")
+	commonFlags.StringP("preview-format", "p", "describe", "The format of preview. One of: describe|yaml|json|jsonpath=...|custom-columns=...|logs|events|top|template=...|secret")
+	commonFlags.Int("logs-tail", 100, "The number of lines of recent logs on the logs preview. All lines are shown if it's -1")
+	commonFlags.String("logs-since", "", "Show only logs newer than a relative duration like 5m on the logs preview")
+	commonFlags.Bool("logs-previous", false, "Show logs of the previous instance of containers on the logs preview at first. Logs of current and previous ones are toggled by alt-p")
+	for name, completion := range map[string]func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective){
+		"namespace":      command.CompleteNamespaces,
+		"context":        command.CompleteContexts,
+		"preview-format": command.CompletePreviewFormats,
+	} {
+		if err := cli.RegisterFlagCompletionFunc(name, completion); err != nil {
+			panic(err)
+		}
+	}
+
+	flags := cli.Flags()
+	flags.StringP("output", "o", "", "Output format. One of: name|kind/name|namespace/name|json|yaml|go-template=...|go-template-file=...|jsonpath=...|jsonpath-file=... (default \"name\", or \"namespace/name\" with --all-namespaces)")
+	flags.BoolP("null", "0", false, "Separate output items by NUL instead of newline for xargs -0")
+	flags.Bool("select-container", false, "Select a container of each selected pod, and output the name with -c container")
+	flags.Bool("navigate", false, "List objects owned by the object on the cursor by ctrl-o, and go back or list its owners by ctrl-b")
+
+	for _, action := range command.ActionNames() {
+		cli.AddCommand(newActionCommand(action))
+	}
+	cli.AddCommand(newContextCommand(), newNamespaceCommand(), newInitCommand(), newCompleteCommand(), newDecodeSecretCommand())
 
 	if err := cli.Execute(); err != nil {
+		code := exitCode(err)
+		// The message of an interactive action is already shown by kubectl
+		var exitErr *command.ExitError
+		if code == exitCodeCanceled || errors.As(err, &exitErr) {
+			os.Exit(code)
+		}
 		message := err.Error()
 		if !strings.HasSuffix(message, "\n") {
 			message = message + "\n"
@@ -62,7 +159,7 @@ func main() {
 		if werr != nil {
 			fmt.Printf("failed to write the message %s on stderr", message)
 		}
-		os.Exit(1)
+		os.Exit(code)
 	}
 	os.Exit(0)
 }
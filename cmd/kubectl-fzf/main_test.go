@@ -0,0 +1,78 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/at-ishikawa/kubectl-fzf/internal/command"
+)
+
+func TestExitCode(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{
+			name: "canceled",
+			err:  fmt.Errorf("failed to select objects: %w", command.ErrCanceled),
+			want: exitCodeCanceled,
+		},
+		{
+			name: "no matches",
+			err:  command.ErrNoMatch,
+			want: exitCodeNoMatch,
+		},
+		{
+			name: "no objects",
+			err:  command.ErrNoObjects,
+			want: exitCodeNoMatch,
+		},
+		{
+			name: "fzf is not found",
+			err:  command.ErrFinderNotFound,
+			want: exitCodeFinderNotFound,
+		},
+		{
+			name: "namespace not found",
+			err:  command.ErrNamespaceNotFound,
+			want: exitCodeKubectl,
+		},
+		{
+			name: "unknown resource",
+			err:  command.ErrUnknownResource,
+			want: exitCodeKubectl,
+		},
+		{
+			name: "forbidden",
+			err:  command.ErrForbidden,
+			want: exitCodeKubectl,
+		},
+		{
+			name: "kubectl error",
+			err:  &command.KubectlError{Args: []string{"get", "pods"}, Err: errors.New("exit status 1")},
+			want: exitCodeKubectl,
+		},
+		{
+			name: "exit code of an interactive action",
+			err: &command.ExitError{
+				Code: 42,
+				Err:  &command.KubectlError{Args: []string{"exec", "pod1"}, Err: errors.New("exit status 42")},
+			},
+			want: 42,
+		},
+		{
+			name: "other errors",
+			err:  errors.New("invalid argument"),
+			want: exitCodeError,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, exitCode(tc.err))
+		})
+	}
+}
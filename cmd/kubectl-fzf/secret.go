@@ -0,0 +1,36 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/at-ishikawa/kubectl-fzf/internal/command"
+)
+
+func newDecodeSecretCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "decode-secret",
+		Short:  "Show a secret in JSON from stdin with decoded values for the secret preview",
+		Args:   cobra.NoArgs,
+		Hidden: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			config, err := loadConfig(cmd)
+			if err != nil {
+				return err
+			}
+			reveal, err := cmd.Flags().GetBool("reveal")
+			if err != nil {
+				return err
+			}
+			cli, err := command.NewSecretCli(reveal, config)
+			if err != nil {
+				return err
+			}
+			return cli.Run(context.Background(), os.Stdin, os.Stdout, os.Stderr)
+		},
+	}
+	cmd.Flags().Bool("reveal", false, "Show values instead of masking them")
+	return cmd
+}
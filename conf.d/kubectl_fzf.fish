@@ -4,3 +4,4 @@ bind \cx\ck\cs 'kubectl_fzf service'
 bind \cx\ck\cc 'kubectl_fzf configmap'
 bind \cx\ck\ch 'kubectl_fzf horizontalpodautoscaler'
 bind \cx\ck\ca 'kubectl_fzf all'
+bind \cx\ck\ck 'kubectl_fzf'
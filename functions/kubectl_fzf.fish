@@ -1,5 +1,11 @@
 function kubectl_fzf -d "Run kubectl with the fzf finder"
     set -l resource $argv[1]
+    # Nothing is inserted if it's canceled or fails
     set -l selected (kubectl fzf $resource)
-    commandline -i (echo $selected)
+    or begin
+        commandline -f repaint
+        return
+    end
+    commandline -i -- (string join ' ' $selected)
+    commandline -f repaint
 end
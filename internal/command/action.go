@@ -0,0 +1,198 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+type actionCli struct {
+	getCli      *getCli
+	kubectl     Kubectl
+	resource    string
+	action      kubectlAction
+	options     map[string]string
+	commandArgs []string
+}
+
+type kubectlAction struct {
+	operation string
+	options   map[string]string
+	// runEach is true if the operation accepts only one object at once
+	runEach     bool
+	commandArgs []string
+	// selectContainer is true if a container of a pod is selected for the operation
+	selectContainer bool
+	// interactive is true if the operation runs on the terminal until the user exits, like exec
+	interactive bool
+}
+
+var (
+	kubectlActions = map[string]kubectlAction{
+		"describe": {
+			operation: "describe",
+		},
+		"delete": {
+			operation: "delete",
+		},
+		"edit": {
+			operation: "edit",
+		},
+		"logs": {
+			operation:       "logs",
+			runEach:         true,
+			selectContainer: true,
+		},
+		"exec": {
+			operation: "exec",
+			options: map[string]string{
+				"--stdin": "true",
+				"--tty":   "true",
+			},
+			runEach: true,
+			commandArgs: []string{
+				"sh",
+			},
+			selectContainer: true,
+			interactive:     true,
+		},
+	}
+)
+
+// ActionNames returns the names of actions which can be run on selected objects.
+func ActionNames() []string {
+	names := make([]string, 0, len(kubectlActions))
+	for name := range kubectlActions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActionSelectsContainers returns true if a container of each selected pod is selected for the action,
+// which is passed to NewGetCli by GetOptions.SelectContainers.
+func ActionSelectsContainers(backend kubectlBackend, actionName string) bool {
+	return kubectlActions[actionName].selectContainer && isPodResource(backend.resourceKubectl())
+}
+
+// NewActionCli returns the cli to run the action on objects selected by cli
+func NewActionCli(backend kubectlBackend, cli *getCli, actionName string, options map[string]string, commandArgs []string) (*actionCli, error) {
+	actionCli, err := newActionCli(backend, actionName, options, commandArgs)
+	if err != nil {
+		return nil, err
+	}
+	actionCli.getCli = cli
+	return actionCli, nil
+}
+
+// newActionCli returns the cli to run the action on objects without the cli to select them
+func newActionCli(backend kubectlBackend, actionName string, options map[string]string, commandArgs []string) (*actionCli, error) {
+	k := backend.resourceKubectl()
+	action, ok := kubectlActions[actionName]
+	if !ok {
+		return nil, fmt.Errorf("action must be one of [%s]", strings.Join(ActionNames(), ", "))
+	}
+	resource := k.resource
+	if k.hasMultipleResources() {
+		resource = ""
+	}
+	if len(commandArgs) == 0 {
+		commandArgs = action.commandArgs
+	}
+	mergedOptions := make(map[string]string, len(action.options)+len(options))
+	for k, v := range action.options {
+		mergedOptions[k] = v
+	}
+	for k, v := range options {
+		mergedOptions[k] = v
+	}
+	return &actionCli{
+		kubectl:     backend,
+		resource:    resource,
+		action:      action,
+		options:     mergedOptions,
+		commandArgs: commandArgs,
+	}, nil
+}
+
+func (c actionCli) Run(ctx context.Context, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	objects, key, err := c.getCli.selectObjectsWithKey(ctx, ioErr)
+	if err != nil {
+		return err
+	}
+	// Another action is run by a key of --expect
+	if action, ok := c.getCli.expectActions[key]; ok {
+		return c.getCli.runExpectAction(ctx, action, objects, ioIn, ioOut, ioErr)
+	}
+	return c.run(ctx, objects, ioIn, ioOut, ioErr)
+}
+
+// run runs the action on the objects
+func (c actionCli) run(ctx context.Context, objects []resourceObject, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	if c.action.runEach {
+		for _, object := range objects {
+			if err := c.kubectl.runWithIO(ctx, c.action.operation, "", []string{c.eachObjectName(object)}, c.objectOptions(object), c.commandArgs, ioIn, ioOut, ioErr); err != nil {
+				return c.exitError(err)
+			}
+		}
+		return nil
+	}
+
+	namespaces, namesByNamespace := groupByNamespace(objects)
+	for _, namespace := range namespaces {
+		options := c.objectOptions(resourceObject{
+			namespace: namespace,
+		})
+		if err := c.kubectl.runWithIO(ctx, c.action.operation, c.resource, namesByNamespace[namespace], options, c.commandArgs, ioIn, ioOut, ioErr); err != nil {
+			return c.exitError(err)
+		}
+	}
+	return nil
+}
+
+// eachObjectName returns the argument of the object for operations accepting only one object like logs or exec,
+// which read the resource type as the name of a pod if it's passed as another argument
+func (c actionCli) eachObjectName(object resourceObject) string {
+	if c.resource == "" || isPodResource(&kubectl{resource: c.resource}) {
+		return object.name
+	}
+	return c.resource + "/" + object.name
+}
+
+// exitError returns ExitError with the exit code of kubectl for interactive actions like exec or logs --follow,
+// which is the exit code of the command in the container for exec
+func (c actionCli) exitError(err error) error {
+	if !c.action.interactive && c.options["--follow"] != "true" {
+		return err
+	}
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return err
+	}
+	return &ExitError{
+		Code: exitErr.ExitCode(),
+		Err:  err,
+	}
+}
+
+// objectOptions returns options with the namespace and the container of the object
+func (c actionCli) objectOptions(object resourceObject) map[string]string {
+	if object.namespace == "" && object.container == "" {
+		return c.options
+	}
+	options := map[string]string{}
+	if object.namespace != "" {
+		options["-n"] = object.namespace
+	}
+	if object.container != "" {
+		options["-c"] = object.container
+	}
+	for k, v := range c.options {
+		options[k] = v
+	}
+	return options
+}
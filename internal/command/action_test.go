@@ -0,0 +1,471 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewActionCli(t *testing.T) {
+	getCli := &getCli{}
+	testCases := []struct {
+		name        string
+		kubectl     *kubectl
+		action      string
+		options     map[string]string
+		commandArgs []string
+		want        *actionCli
+		wantErr     error
+	}{
+		{
+			name: "describe a single resource",
+			kubectl: &kubectl{
+				resource:  kubernetesResourcePods,
+				namespace: "default",
+			},
+			action: "describe",
+			want: &actionCli{
+				getCli: getCli,
+				kubectl: &kubectl{
+					resource:  kubernetesResourcePods,
+					namespace: "default",
+				},
+				resource: kubernetesResourcePods,
+				action:   kubectlActions["describe"],
+				options:  map[string]string{},
+			},
+		},
+		{
+			name: "logs with options for multiple resources",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods + "," + kubernetesResourceService,
+			},
+			action: "logs",
+			options: map[string]string{
+				"--follow": "true",
+			},
+			want: &actionCli{
+				getCli: getCli,
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods + "," + kubernetesResourceService,
+				},
+				resource: "",
+				action:   kubectlActions["logs"],
+				options: map[string]string{
+					"--follow": "true",
+				},
+			},
+		},
+		{
+			name: "exec with the default command",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			action: "exec",
+			want: &actionCli{
+				getCli: getCli,
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				resource: kubernetesResourcePods,
+				action:   kubectlActions["exec"],
+				options: map[string]string{
+					"--stdin": "true",
+					"--tty":   "true",
+				},
+				commandArgs: []string{"sh"},
+			},
+		},
+		{
+			name: "exec with a command",
+			kubectl: &kubectl{
+				resource: kubernetesResourceAll,
+			},
+			action:      "exec",
+			commandArgs: []string{"bash", "-l"},
+			want: &actionCli{
+				getCli: getCli,
+				kubectl: &kubectl{
+					resource: kubernetesResourceAll,
+				},
+				resource: "",
+				action:   kubectlActions["exec"],
+				options: map[string]string{
+					"--stdin": "true",
+					"--tty":   "true",
+				},
+				commandArgs: []string{"bash", "-l"},
+			},
+		},
+		{
+			name: "unknown action",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			action:  "apply",
+			wantErr: errors.New("action must be one of [delete, describe, edit, exec, logs]"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := NewActionCli(tc.kubectl, getCli, tc.action, tc.options, tc.commandArgs)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+func TestActionCli_Run(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	defaultErr := errors.New("error")
+	exitCodeErr := &KubectlError{
+		Args: []string{"exec", "pod1"},
+		Err:  newExitError(t, 42),
+	}
+	testCases := []struct {
+		name              string
+		action            string
+		resource          string
+		allNamespaces     bool
+		fzfOut            string
+		fzfErr            error
+		wantRunNames      [][]string
+		wantRunNamespaces []string
+		runErr            error
+		wantErr           error
+		// wantExitCode is the code of ExitError, or 0 if it's not returned
+		wantExitCode int
+	}{
+		{
+			name:         "describe multiple objects at once",
+			action:       "describe",
+			resource:     kubernetesResourcePods,
+			fzfOut:       "pod1 1/1 Running 2d\npod2 1/1 Running 2d\n",
+			wantRunNames: [][]string{{"pod1", "pod2"}},
+		},
+		{
+			name:         "logs of each object for multiple resources",
+			action:       "logs",
+			resource:     "",
+			fzfOut:       "pod/pod1 1/1 Running 2d\nservice/svc1 ClusterIP 10.0.0.1\n",
+			wantRunNames: [][]string{{"pod/pod1"}, {"service/svc1"}},
+		},
+		{
+			name:              "delete objects in each namespace",
+			action:            "delete",
+			resource:          kubernetesResourcePods,
+			allNamespaces:     true,
+			fzfOut:            "default pod1 1/1 Running 2d\nkube-system pod2 1/1 Running 2d\ndefault pod3 1/1 Running 2d\n",
+			wantRunNames:      [][]string{{"pod1", "pod3"}, {"pod2"}},
+			wantRunNamespaces: []string{"default", "kube-system"},
+		},
+		{
+			name:     "canceled",
+			action:   "delete",
+			resource: kubernetesResourcePods,
+			fzfOut:   "",
+		},
+		{
+			name:     "fzf error",
+			action:   "delete",
+			resource: kubernetesResourcePods,
+			fzfErr:   defaultErr,
+			wantErr:  defaultErr,
+		},
+		{
+			name:         "kubectl error",
+			action:       "exec",
+			resource:     kubernetesResourcePods,
+			fzfOut:       "pod1 1/1 Running 2d\npod2 1/1 Running 2d\n",
+			wantRunNames: [][]string{{"pod1"}},
+			runErr:       defaultErr,
+			wantErr:      defaultErr,
+		},
+		{
+			name:         "exit code of the command of exec",
+			action:       "exec",
+			resource:     kubernetesResourcePods,
+			fzfOut:       "pod1 1/1 Running 2d\n",
+			wantRunNames: [][]string{{"pod1"}},
+			runErr:       exitCodeErr,
+			wantErr:      exitCodeErr,
+			wantExitCode: 42,
+		},
+		{
+			name:         "exit code of kubectl describe is not passed through",
+			action:       "describe",
+			resource:     kubernetesResourcePods,
+			fzfOut:       "pod1 1/1 Running 2d\n",
+			wantRunNames: [][]string{{"pod1"}},
+			runErr:       exitCodeErr,
+			wantErr:      exitCodeErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), "get", gomock.Any(), gomock.Any()).
+				Return([]byte("NAME READY STATUS AGE\npod1 1/1 Running 2d\npod2 1/1 Running 2d"), nil).
+				Times(1)
+			action := kubectlActions[tc.action]
+			runResource := tc.resource
+			if action.runEach {
+				runResource = ""
+			}
+			for i, names := range tc.wantRunNames {
+				options := action.options
+				if tc.wantRunNamespaces != nil {
+					options = map[string]string{
+						"-n": tc.wantRunNamespaces[i],
+					}
+				}
+				mockKubectl.EXPECT().
+					runWithIO(gomock.Any(), action.operation, runResource, names, options, action.commandArgs, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(tc.runErr).
+					Times(1)
+			}
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				if tc.fzfErr != nil {
+					return nil, tc.fzfErr
+				}
+				if tc.fzfOut == "" {
+					return nil, nil
+				}
+				return []byte(tc.fzfOut), nil
+			}
+
+			sut := actionCli{
+				getCli: &getCli{
+					kubectl:       mockKubectl,
+					allNamespaces: tc.allNamespaces,
+				},
+				kubectl:     mockKubectl,
+				resource:    tc.resource,
+				action:      action,
+				options:     action.options,
+				commandArgs: action.commandArgs,
+			}
+			var gotIOOut bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, &bytes.Buffer{})
+			assert.True(t, errors.Is(gotErr, tc.wantErr))
+			var gotExitErr *ExitError
+			if tc.wantExitCode == 0 {
+				assert.False(t, errors.As(gotErr, &gotExitErr))
+			} else if assert.True(t, errors.As(gotErr, &gotExitErr)) {
+				assert.Equal(t, tc.wantExitCode, gotExitErr.Code)
+			}
+			assert.Equal(t, "", gotIOOut.String())
+		})
+	}
+}
+
+func TestActionCli_Run_selectContainer(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockKubectl := NewMockKubectl(mockCtrl)
+	mockKubectl.EXPECT().
+		run(gomock.Any(), "get", nil, gomock.Any()).
+		Return([]byte("NAMESPACE NAME READY STATUS AGE\ndefault pod1 2/2 Running 2d\nkube-system pod2 1/1 Running 2d"), nil).
+		Times(1)
+	mockKubectl.EXPECT().
+		run(gomock.Any(), "get", []string{"pod1"}, map[string]string{"-o": "json", "-n": "default"}).
+		Return([]byte(multiContainerPodJSON), nil).
+		Times(1)
+	mockKubectl.EXPECT().
+		run(gomock.Any(), "get", []string{"pod2"}, map[string]string{"-o": "json", "-n": "kube-system"}).
+		Return([]byte(`{"spec": {"containers": [{"name": "coredns", "image": "coredns:1.11"}]}}`), nil).
+		Times(1)
+	mockKubectl.EXPECT().
+		getCommand("logs", "", []string{"pod1"}, gomock.Any()).
+		Return("kubectl logs pod1 -c={1}").
+		Times(1)
+
+	action := kubectlActions["exec"]
+	gomock.InOrder(
+		mockKubectl.EXPECT().
+			runWithIO(gomock.Any(), "exec", "", []string{"pod1"}, map[string]string{
+				"-n":      "default",
+				"-c":      "istio-proxy",
+				"--stdin": "true",
+				"--tty":   "true",
+			}, action.commandArgs, gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil),
+		mockKubectl.EXPECT().
+			runWithIO(gomock.Any(), "exec", "", []string{"pod2"}, map[string]string{
+				"-n":      "kube-system",
+				"-c":      "coredns",
+				"--stdin": "true",
+				"--tty":   "true",
+			}, action.commandArgs, gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil),
+	)
+	fzfOuts := []string{
+		"default pod1 2/2 Running 2d\nkube-system pod2 1/1 Running 2d\n",
+		"istio-proxy container istio/proxyv2:1.20 true 0\n",
+	}
+	runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+		out := fzfOuts[0]
+		fzfOuts = fzfOuts[1:]
+		return []byte(out), nil
+	}
+
+	cli := &getCli{
+		kubectl:          mockKubectl,
+		allNamespaces:    true,
+		selectContainers: true,
+		config:           &resolvedConfig{logsPreview: defaultLogsPreviewOptions},
+	}
+	sut, err := NewActionCli(&kubectl{resource: kubernetesResourcePods, allNamespaces: true}, cli, "exec", nil, nil)
+	assert.NoError(t, err)
+	sut.kubectl = mockKubectl
+
+	gotErr := sut.Run(context.Background(), strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+	assert.NoError(t, gotErr)
+	assert.Empty(t, fzfOuts)
+}
+
+func TestActionCli_run_arguments(t *testing.T) {
+	backupRunKubectlWithIO := runKubectlWithIO
+	defer func() {
+		runKubectlWithIO = backupRunKubectlWithIO
+	}()
+
+	testCases := []struct {
+		name        string
+		kubectl     *kubectl
+		action      string
+		commandArgs []string
+		objects     []resourceObject
+		wantArgs    [][]string
+	}{
+		{
+			name:    "logs of a pod",
+			kubectl: &kubectl{resource: kubernetesResourcePods, namespace: "default"},
+			action:  "logs",
+			objects: []resourceObject{{name: "pod1", container: "app"}},
+			wantArgs: [][]string{
+				{"logs", "pod1", "-n=default", "-c=app"},
+			},
+		},
+		{
+			name:    "logs of a service",
+			kubectl: &kubectl{resource: kubernetesResourceService},
+			action:  "logs",
+			objects: []resourceObject{{name: "svc1"}, {name: "svc2"}},
+			wantArgs: [][]string{
+				{"logs", "svc/svc1"},
+				{"logs", "svc/svc2"},
+			},
+		},
+		{
+			name:    "logs for multiple resources",
+			kubectl: &kubectl{resource: "pods,deployments"},
+			action:  "logs",
+			objects: []resourceObject{{name: "deployment.apps/app"}},
+			wantArgs: [][]string{
+				{"logs", "deployment.apps/app"},
+			},
+		},
+		{
+			name:        "exec in a pod of each namespace",
+			kubectl:     &kubectl{resource: "po", allNamespaces: true},
+			action:      "exec",
+			commandArgs: []string{"bash"},
+			objects:     []resourceObject{{namespace: "default", name: "pod1", container: "app"}},
+			wantArgs: [][]string{
+				{"exec", "pod1", "--stdin=true", "--tty=true", "-c=app", "-n=default", "--", "bash"},
+			},
+		},
+		{
+			name:    "exec in a deployment",
+			kubectl: &kubectl{resource: "deployments", namespace: "default"},
+			action:  "exec",
+			objects: []resourceObject{{name: "app"}},
+			wantArgs: [][]string{
+				{"exec", "deployments/app", "-n=default", "--stdin=true", "--tty=true", "--", "sh"},
+			},
+		},
+		{
+			name:    "describe objects of a resource at once",
+			kubectl: &kubectl{resource: kubernetesResourceService},
+			action:  "describe",
+			objects: []resourceObject{{name: "svc1"}, {name: "svc2"}},
+			wantArgs: [][]string{
+				{"describe", "svc", "svc1", "svc2"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotArgs [][]string
+			runKubectlWithIO = func(ctx context.Context, args []string, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+				gotArgs = append(gotArgs, args)
+				return nil
+			}
+			sut, err := newActionCli(tc.kubectl, tc.action, nil, tc.commandArgs)
+			require.NoError(t, err)
+
+			gotErr := sut.run(context.Background(), tc.objects, strings.NewReader(""), &bytes.Buffer{}, &bytes.Buffer{})
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.wantArgs, gotArgs)
+		})
+	}
+}
+
+func TestActionSelectsContainers(t *testing.T) {
+	testCases := []struct {
+		name     string
+		resource string
+		action   string
+		want     bool
+	}{
+		{
+			name:     "exec for pods",
+			resource: kubernetesResourcePods,
+			action:   "exec",
+			want:     true,
+		},
+		{
+			name:     "logs for pods",
+			resource: "po",
+			action:   "logs",
+			want:     true,
+		},
+		{
+			name:     "describe for pods",
+			resource: kubernetesResourcePods,
+			action:   "describe",
+			want:     false,
+		},
+		{
+			name:     "logs for services",
+			resource: kubernetesResourceService,
+			action:   "logs",
+			want:     false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, ActionSelectsContainers(&kubectl{resource: tc.resource}, tc.action))
+		})
+	}
+}
@@ -0,0 +1,189 @@
+package command
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+const (
+	bindingReload        = "reload"
+	bindingLogs          = "logs"
+	bindingExec          = "exec"
+	bindingDelete        = "delete"
+	bindingTogglePreview = "toggle-preview"
+	// bindingTogglePreviousLogs toggles the logs preview between current and previous containers
+	bindingTogglePreviousLogs = "toggle-previous-logs"
+	// bindingRevealSecret toggles the secret preview between masked and revealed values
+	bindingRevealSecret = "reveal-secret"
+
+	// bindingExecCommand is the command run in a container by the exec binding
+	bindingExecCommand = "sh"
+)
+
+var (
+	// bindingNames are names of bindings running kubectl in the order of fzf arguments
+	bindingNames = []string{bindingReload, bindingLogs, bindingExec, bindingDelete, bindingTogglePreview, bindingTogglePreviousLogs, bindingRevealSecret}
+	// defaultBindingKeys are the keys of bindings by default
+	defaultBindingKeys = map[string]string{
+		bindingReload:             "ctrl-r",
+		bindingLogs:               "ctrl-l",
+		bindingExec:               "ctrl-e",
+		bindingDelete:             "ctrl-d",
+		bindingTogglePreview:      "ctrl-y",
+		bindingTogglePreviousLogs: "alt-p",
+		bindingRevealSecret:       "alt-r",
+	}
+
+	// fzfPlaceholderRegexp matches placeholders of fzf replaced with the row, like {1} or {}
+	fzfPlaceholderRegexp = regexp.MustCompile(`\{\+?[0-9,.-]*\}|\{[qn]\}`)
+	// fzfActionDelimiters are pairs of delimiters of an action argument, like reload(command)
+	fzfActionDelimiters = []string{"()", "[]", "<>", "~~", "!!", "@@", "##"}
+)
+
+// getBindings returns key bindings of fzf running kubectl for the object on the cursor.
+// Commands are built for the same resource and namespace as the preview command.
+func getBindings(k *kubectl, previewFormat string, previewCommand string, reloadCommand string, config *resolvedConfig) ([]fzfBinding, error) {
+	target := newPreviewTarget(k)
+	isPod := isPodResource(k)
+
+	var bindings []fzfBinding
+	for _, name := range bindingNames {
+		key := config.bindingKeys[name]
+		if key == "" {
+			continue
+		}
+		var action string
+		switch name {
+		case bindingReload:
+			action = "reload:" + reloadCommand
+		case bindingLogs:
+			if !isPod {
+				continue
+			}
+			action = "execute:" + k.getCommand("logs", "", []string{target.name}, target.options(map[string]string{
+				"--all-containers": "true",
+			})) + " | ${PAGER:-less}"
+		case bindingExec:
+			if !isPod {
+				continue
+			}
+			action = "execute:" + k.getCommand("exec", "", []string{target.name}, target.options(map[string]string{
+				"--stdin": "true",
+				"--tty":   "true",
+			})) + " -- " + bindingExecCommand
+		case bindingDelete:
+			object := target.name
+			if target.allNamespaces {
+				object = target.namespace + "/" + target.name
+			}
+			deleteCommand := fmt.Sprintf(`printf 'Delete %%s? [y/N] ' %s && read -r answer && [ "$answer" = y ] && %s`,
+				object,
+				k.getCommand("delete", target.resource, []string{target.name}, target.options(nil)))
+			action = getFzfAction("execute", deleteCommand) + "+" + getFzfAction("reload", reloadCommand)
+		case bindingTogglePreview:
+			var err error
+			action, err = getTogglePreviewAction(k, previewFormat, previewCommand, config)
+			if err != nil {
+				return nil, err
+			}
+			if action == "" {
+				continue
+			}
+		case bindingTogglePreviousLogs:
+			if previewFormat != previewFormatLogs {
+				continue
+			}
+			action = getTogglePreviousLogsAction(k, target, config.logsPreview)
+		case bindingRevealSecret:
+			if previewFormat != previewFormatSecret || config.secretRevealDisabled {
+				continue
+			}
+			var err error
+			action, err = getRevealSecretAction(k, target)
+			if err != nil {
+				return nil, err
+			}
+		}
+		bindings = append(bindings, fzfBinding{key: key, action: action})
+	}
+	return bindings, nil
+}
+
+// getTogglePreviewAction returns the fzf action to toggle the preview between the format and yaml, or describe for yaml.
+// Secrets are toggled to describe instead of yaml not to show values on the secret preview or if they cannot be revealed,
+// and an empty action is returned if the format is describe.
+// The preview label is used as the state, and the action requires fzf >= 0.45 for transform.
+func getTogglePreviewAction(k *kubectl, previewFormat string, previewCommand string, config *resolvedConfig) (string, error) {
+	formatName := previewFormat
+	if i := strings.Index(previewFormat, "="); i >= 0 {
+		formatName = previewFormat[:i]
+	}
+	toggledFormat := kubectlOutputFormatYaml
+	if formatName == kubectlOutputFormatYaml {
+		toggledFormat = kubectlOutputFormatDescribe
+	}
+	if formatName == previewFormatSecret || (config.secretRevealDisabled && hasSecretResource(k.resource)) {
+		toggledFormat = kubectlOutputFormatDescribe
+	}
+	if toggledFormat == formatName {
+		return "", nil
+	}
+	toggledCommand, err := getPreviewCommand(k, toggledFormat, config)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`transform:[ "$FZF_PREVIEW_LABEL" = %s ] && printf %%s %s || printf %%s %s`,
+		toggledFormat,
+		getChangePreviewArgument(formatName, previewCommand),
+		getChangePreviewArgument(toggledFormat, toggledCommand)), nil
+}
+
+// getTogglePreviousLogsAction returns the fzf action to toggle the logs preview between current and previous containers.
+// The preview label is used as the state in the same way as getTogglePreviewAction.
+func getTogglePreviousLogsAction(k *kubectl, target previewTarget, options LogsPreviewOptions) string {
+	label, command := previewFormatLogs, getLogsPreviewCommand(k, target, options, false)
+	toggledLabel, toggledCommand := previewLabelPreviousLogs, getLogsPreviewCommand(k, target, options, true)
+	if options.Previous {
+		label, command, toggledLabel, toggledCommand = toggledLabel, toggledCommand, label, command
+	}
+	return fmt.Sprintf(`transform:[ "$FZF_PREVIEW_LABEL" = %s ] && printf %%s %s || printf %%s %s`,
+		toggledLabel,
+		getChangePreviewArgument(label, command),
+		getChangePreviewArgument(toggledLabel, toggledCommand))
+}
+
+// getRevealSecretAction returns the fzf action to toggle the secret preview between masked and revealed values.
+// The preview label is used as the state in the same way as getTogglePreviewAction.
+func getRevealSecretAction(k *kubectl, target previewTarget) (string, error) {
+	command, err := getSecretPreviewCommand(k, target, false)
+	if err != nil {
+		return "", err
+	}
+	revealedCommand, err := getSecretPreviewCommand(k, target, true)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`transform:[ "$FZF_PREVIEW_LABEL" = %s ] && printf %%s %s || printf %%s %s`,
+		previewLabelRevealedSecret,
+		getChangePreviewArgument(previewFormatSecret, command),
+		getChangePreviewArgument(previewLabelRevealedSecret, revealedCommand)), nil
+}
+
+// getChangePreviewArgument returns the quoted actions to change the preview label and command, which are output by a transform command
+func getChangePreviewArgument(label string, command string) string {
+	// Placeholders are escaped not to be replaced in the transform command, but in the changed preview command
+	command = fzfPlaceholderRegexp.ReplaceAllString(command, `\$0`)
+	return quoteArgument("change-preview-label(" + label + ")+change-preview:" + command)
+}
+
+// getFzfAction returns the fzf action with the argument enclosed by delimiters not used in it.
+// If all delimiters are used, the argument follows a colon, and the action must be the last one.
+func getFzfAction(action string, argument string) string {
+	for _, delimiters := range fzfActionDelimiters {
+		if !strings.ContainsRune(argument, rune(delimiters[1])) {
+			return action + delimiters[:1] + argument + delimiters[1:]
+		}
+	}
+	return action + ":" + argument
+}
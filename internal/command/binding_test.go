@@ -0,0 +1,207 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetBindings(t *testing.T) {
+	backupExecutable := executable
+	defer func() {
+		executable = backupExecutable
+	}()
+	executable = func() (string, error) {
+		return "/usr/local/bin/kubectl-fzf", nil
+	}
+
+	testCases := []struct {
+		name          string
+		kubectl       *kubectl
+		previewFormat string
+		bindingKeys   map[string]string
+		// previousLogs is true to show logs of previous containers at first
+		previousLogs         bool
+		secretRevealDisabled bool
+		want                 []fzfBinding
+	}{
+		{
+			name: "pods in a namespace",
+			kubectl: &kubectl{
+				resource:  "pods",
+				namespace: "default",
+			},
+			previewFormat: kubectlOutputFormatDescribe,
+			bindingKeys:   defaultBindingKeys,
+			want: []fzfBinding{
+				{key: "ctrl-r", action: "reload:kubectl get pods -n=default"},
+				{key: "ctrl-l", action: "execute:kubectl logs {1} -n=default --all-containers=true | ${PAGER:-less}"},
+				{key: "ctrl-e", action: "execute:kubectl exec {1} -n=default --stdin=true --tty=true -- sh"},
+				{key: "ctrl-d", action: `execute(printf 'Delete %s? [y/N] ' {1} && read -r answer && [ "$answer" = y ] && kubectl delete pods {1} -n=default)+reload(kubectl get pods -n=default)`},
+				{key: "ctrl-y", action: `transform:[ "$FZF_PREVIEW_LABEL" = yaml ] && printf %s 'change-preview-label(describe)+change-preview:kubectl describe pods \{1} -n=default' || printf %s 'change-preview-label(yaml)+change-preview:kubectl get pods \{1} -n=default -o=yaml'`},
+			},
+		},
+		{
+			name: "multiple resources across all namespaces",
+			kubectl: &kubectl{
+				resource:      "pods,services",
+				allNamespaces: true,
+			},
+			previewFormat: kubectlOutputFormatYaml,
+			bindingKeys:   defaultBindingKeys,
+			want: []fzfBinding{
+				{key: "ctrl-r", action: "reload:kubectl get pods,services"},
+				{key: "ctrl-d", action: `execute(printf 'Delete %s? [y/N] ' {1}/{2} && read -r answer && [ "$answer" = y ] && kubectl delete {2} -n={1})+reload(kubectl get pods,services)`},
+				{key: "ctrl-y", action: `transform:[ "$FZF_PREVIEW_LABEL" = describe ] && printf %s 'change-preview-label(yaml)+change-preview:kubectl get \{2} -n=\{1} -o=yaml' || printf %s 'change-preview-label(describe)+change-preview:kubectl describe \{2} -n=\{1}'`},
+			},
+		},
+		{
+			name: "keys changed by the config",
+			kubectl: &kubectl{
+				resource: "deployments",
+			},
+			previewFormat: "jsonpath={.metadata.name}",
+			bindingKeys: map[string]string{
+				bindingReload:        "f5",
+				bindingTogglePreview: "ctrl-t",
+			},
+			want: []fzfBinding{
+				{key: "f5", action: "reload:kubectl get deployments"},
+				{key: "ctrl-t", action: `transform:[ "$FZF_PREVIEW_LABEL" = yaml ] && printf %s 'change-preview-label(jsonpath)+change-preview:kubectl get deployments \{1} -o=jsonpath={.metadata.name}' || printf %s 'change-preview-label(yaml)+change-preview:kubectl get deployments \{1} -o=yaml'`},
+			},
+		},
+		{
+			name: "logs of pods",
+			kubectl: &kubectl{
+				resource:  "pods",
+				namespace: "default",
+			},
+			previewFormat: previewFormatLogs,
+			bindingKeys: map[string]string{
+				bindingTogglePreviousLogs: "alt-p",
+			},
+			want: []fzfBinding{
+				{key: "alt-p", action: `transform:[ "$FZF_PREVIEW_LABEL" = previous-logs ] && printf %s 'change-preview-label(logs)+change-preview:kubectl logs \{1} -n=default --all-containers=true --prefix=true --tail=100' || printf %s 'change-preview-label(previous-logs)+change-preview:kubectl logs \{1} -n=default --all-containers=true --prefix=true --previous=true --tail=100'`},
+			},
+		},
+		{
+			name: "previous logs of a deployment",
+			kubectl: &kubectl{
+				resource: "deployments",
+			},
+			previewFormat: previewFormatLogs,
+			bindingKeys: map[string]string{
+				bindingTogglePreviousLogs: "alt-p",
+			},
+			previousLogs: true,
+			want: []fzfBinding{
+				{key: "alt-p", action: `transform:[ "$FZF_PREVIEW_LABEL" = logs ] && printf %s 'change-preview-label(previous-logs)+change-preview:kubectl logs deployments/\{1} --all-containers=true --prefix=true --previous=true --tail=100' || printf %s 'change-preview-label(logs)+change-preview:kubectl logs deployments/\{1} --all-containers=true --prefix=true --tail=100'`},
+			},
+		},
+		{
+			name: "secrets",
+			kubectl: &kubectl{
+				resource:  "secrets",
+				namespace: "default",
+			},
+			previewFormat: previewFormatSecret,
+			bindingKeys: map[string]string{
+				bindingRevealSecret: "alt-r",
+			},
+			want: []fzfBinding{
+				{key: "alt-r", action: `transform:[ "$FZF_PREVIEW_LABEL" = revealed-secret ] && printf %s 'change-preview-label(secret)+change-preview:kubectl get secrets \{1} -n=default -o=json | /usr/local/bin/kubectl-fzf decode-secret' || printf %s 'change-preview-label(revealed-secret)+change-preview:kubectl get secrets \{1} -n=default -o=json | /usr/local/bin/kubectl-fzf decode-secret --reveal'`},
+			},
+		},
+		{
+			name: "secrets are toggled to describe instead of yaml on the secret preview",
+			kubectl: &kubectl{
+				resource: "secrets",
+			},
+			previewFormat: previewFormatSecret,
+			bindingKeys: map[string]string{
+				bindingTogglePreview: "ctrl-y",
+			},
+			want: []fzfBinding{
+				{key: "ctrl-y", action: `transform:[ "$FZF_PREVIEW_LABEL" = describe ] && printf %s 'change-preview-label(secret)+change-preview:kubectl get secrets \{1} -o=json | /usr/local/bin/kubectl-fzf decode-secret' || printf %s 'change-preview-label(describe)+change-preview:kubectl describe secrets \{1}'`},
+			},
+		},
+		{
+			name: "secrets are not toggled from describe if they cannot be revealed",
+			kubectl: &kubectl{
+				resource: "pods,secrets",
+			},
+			previewFormat: kubectlOutputFormatDescribe,
+			bindingKeys: map[string]string{
+				bindingTogglePreview: "ctrl-y",
+			},
+			secretRevealDisabled: true,
+		},
+		{
+			name: "secrets cannot be revealed by the config",
+			kubectl: &kubectl{
+				resource: "secrets",
+			},
+			previewFormat: previewFormatSecret,
+			bindingKeys: map[string]string{
+				bindingRevealSecret: "alt-r",
+			},
+			secretRevealDisabled: true,
+		},
+		{
+			name: "previous logs are not toggled for other formats",
+			kubectl: &kubectl{
+				resource: "pods",
+			},
+			previewFormat: kubectlOutputFormatDescribe,
+			bindingKeys: map[string]string{
+				bindingTogglePreviousLogs: "alt-p",
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &resolvedConfig{
+				bindingKeys:          tc.bindingKeys,
+				secretRevealDisabled: tc.secretRevealDisabled,
+				logsPreview:          defaultLogsPreviewOptions,
+			}
+			config.logsPreview.Previous = tc.previousLogs
+			previewCommand, err := getPreviewCommand(tc.kubectl, tc.previewFormat, config)
+			require.NoError(t, err)
+			reloadCommand := tc.kubectl.getCommand("get", tc.kubectl.resource, nil, nil)
+			got, gotErr := getBindings(tc.kubectl, tc.previewFormat, previewCommand, reloadCommand, config)
+			assert.Equal(t, tc.want, got)
+			assert.NoError(t, gotErr)
+		})
+	}
+}
+
+func TestGetFzfAction(t *testing.T) {
+	testCases := []struct {
+		name     string
+		argument string
+		want     string
+	}{
+		{
+			name:     "parentheses",
+			argument: "kubectl get pods",
+			want:     "reload(kubectl get pods)",
+		},
+		{
+			name:     "brackets for an argument with parentheses",
+			argument: "kubectl get pods '--selector=env in (dev)'",
+			want:     "reload[kubectl get pods '--selector=env in (dev)']",
+		},
+		{
+			name:     "colon for an argument with all delimiters",
+			argument: ")]>~!@#",
+			want:     "reload:)]>~!@#",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, getFzfAction("reload", tc.argument))
+		})
+	}
+}
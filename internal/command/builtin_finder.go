@@ -0,0 +1,1031 @@
+package command
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/mattn/go-runewidth"
+)
+
+const (
+	finderPrompt = "> "
+	// finderPreviewRatio is the percentage of the height for the preview, like --preview-window down:70%
+	finderPreviewRatio = 70
+)
+
+var (
+	// finderOptionsWithValue are fzf options taking a value. Other options are ignored unless they are supported
+	finderOptionsWithValue = map[string]bool{
+		"-q": true, "--query": true, "-f": true, "--filter": true,
+		"--header": true, "--header-lines": true,
+		"--preview": true, "--preview-window": true,
+		"--expect": true, "--bind": true, "--listen": true, "--listen-unsafe": true,
+		"--id-nth": true, "--nth": true, "--with-nth": true, "-d": true, "--delimiter": true,
+		"--layout": true, "--height": true, "--prompt": true, "--pointer": true, "--marker": true,
+		"--info": true, "--color": true, "--tiebreak": true, "--border": true,
+	}
+
+	// finderPlaceholderRegexp matches placeholders, and escaped ones like \{1} which are replaced without the backslash
+	finderPlaceholderRegexp = regexp.MustCompile(`\\?\{(q|[0-9]*)\}`)
+
+	// finderActionNameRegexp matches the name of an action at the beginning of actions
+	finderActionNameRegexp = regexp.MustCompile(`^[a-z-]+`)
+	// finderActionsWithArgument are fzf actions taking an argument, which are supported by the builtin finder
+	finderActionsWithArgument = map[string]bool{
+		"reload": true, "execute": true, "execute-silent": true, "transform": true,
+		"change-preview": true, "change-preview-label": true,
+	}
+	// finderActionsWithoutArgument are fzf actions without an argument, which are supported by the builtin finder
+	finderActionsWithoutArgument = map[string]bool{
+		"kill-line": true, "toggle-preview": true,
+		"preview-up": true, "preview-down": true, "preview-page-up": true, "preview-page-down": true,
+	}
+	// finderCommandActions are actions running commands, which are accepted only by --listen-unsafe like fzf
+	finderCommandActions = map[string]bool{
+		"reload": true, "execute": true, "execute-silent": true, "transform": true, "change-preview": true,
+	}
+	// finderUnsupportedEvents are fzf events which are not supported by the builtin finder. Only start is supported.
+	finderUnsupportedEvents = map[string]bool{
+		"load": true, "change": true, "focus": true, "result": true, "resize": true, "one": true, "zero": true,
+		"multi": true, "backward-eof": true, "jump": true, "jump-cancel": true, "click-header": true,
+	}
+
+	// newFinderScreen returns the screen for the builtin finder, which is the terminal
+	newFinderScreen = func() (tcell.Screen, error) {
+		return tcell.NewScreen()
+	}
+	// openFinderTerminal opens the terminal for commands of execute actions
+	openFinderTerminal = func() (*os.File, error) {
+		return os.OpenFile("/dev/tty", os.O_RDWR, 0)
+	}
+)
+
+// finderOptions are fzf options supported by the builtin finder
+type finderOptions struct {
+	multi       bool
+	query       string
+	header      string
+	headerLines int
+	preview     string
+	expect      []string
+	// bindings are actions by each key, or the start event
+	bindings map[string][]finderAction
+	listen   string
+	// listenUnsafe is true to accept actions running commands from the listen server
+	listenUnsafe bool
+	// idFields are the 1-based fields of candidates to keep the cursor and selections across reloads
+	idFields []int
+	// filter is true to output items matching the query without the interaction
+	filter    bool
+	selectOne bool
+	exitZero  bool
+}
+
+// parseFinderOptions parses fzf options for the builtin finder
+func parseFinderOptions(args []string) (finderOptions, error) {
+	options := finderOptions{
+		bindings: map[string][]finderAction{},
+	}
+	for i := 0; i < len(args); i++ {
+		name := args[i]
+		var value string
+		if index := strings.Index(name, "="); strings.HasPrefix(name, "--") && index >= 0 {
+			name, value = name[:index], name[index+1:]
+		} else if finderOptionsWithValue[name] {
+			if i+1 >= len(args) {
+				return finderOptions{}, fmt.Errorf("fzf option %s needs a value", name)
+			}
+			i++
+			value = args[i]
+		}
+
+		switch name {
+		case "-m", "--multi":
+			options.multi = true
+		case "+m", "--no-multi":
+			options.multi = false
+		case "-q", "--query":
+			options.query = value
+		case "-f", "--filter":
+			options.filter = true
+			options.query = value
+		case "-1", "--select-1":
+			options.selectOne = true
+		case "-0", "--exit-0":
+			options.exitZero = true
+		case "--header":
+			options.header = value
+		case "--header-lines":
+			headerLines, err := strconv.Atoi(value)
+			if err != nil || headerLines < 0 {
+				return finderOptions{}, fmt.Errorf("fzf option %s must be a number: %s", name, value)
+			}
+			options.headerLines = headerLines
+		case "--preview":
+			options.preview = value
+		case "--expect":
+			options.expect = append(options.expect, strings.Split(value, ",")...)
+		case "--bind":
+			for _, binding := range parseFzfBindings(value) {
+				if finderUnsupportedEvents[binding.key] {
+					return finderOptions{}, fmt.Errorf("the builtin finder does not support the binding %s:%s: unsupported event %s", binding.key, binding.action, binding.key)
+				}
+				actions, err := parseFinderActions(binding.action)
+				if err != nil {
+					return finderOptions{}, fmt.Errorf("the builtin finder does not support the binding %s:%s: %w", binding.key, binding.action, err)
+				}
+				options.bindings[binding.key] = actions
+			}
+		case "--listen":
+			options.listen = value
+		case "--listen-unsafe":
+			options.listen = value
+			options.listenUnsafe = true
+		case "--id-nth":
+			for _, field := range strings.Split(value, ",") {
+				index, err := strconv.Atoi(field)
+				if err != nil || index < 1 {
+					return finderOptions{}, fmt.Errorf("fzf option %s must be numbers: %s", name, value)
+				}
+				options.idFields = append(options.idFields, index)
+			}
+		}
+	}
+	return options, nil
+}
+
+// finderAction is an fzf action supported by the builtin finder, like reload(command)
+type finderAction struct {
+	name     string
+	argument string
+}
+
+// parseFinderActions parses fzf actions chained by +, like execute(command)+reload(command).
+// It returns an error if an action is not supported.
+func parseFinderActions(value string) ([]finderAction, error) {
+	var actions []finderAction
+	for value != "" {
+		name := finderActionNameRegexp.FindString(value)
+		if name == "" {
+			return nil, fmt.Errorf("invalid action %s", value)
+		}
+		action := finderAction{name: name}
+		rest := value[len(name):]
+		supported := finderActionsWithArgument[name]
+		switch {
+		case rest == "" || rest[0] == '+':
+			supported = finderActionsWithoutArgument[name]
+		case rest[0] == ':':
+			action.argument, rest = rest[1:], ""
+		default:
+			end := -1
+			for _, delimiters := range fzfActionDelimiters {
+				if rest[0] == delimiters[0] {
+					end = strings.IndexByte(rest[1:], delimiters[1])
+					break
+				}
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("invalid action %s", value)
+			}
+			action.argument, rest = rest[1:end+1], rest[end+2:]
+		}
+		if rest != "" && rest[0] != '+' {
+			return nil, fmt.Errorf("invalid action %s", value)
+		}
+		if !supported {
+			return nil, fmt.Errorf("unsupported action %s", name)
+		}
+		actions = append(actions, action)
+		value = strings.TrimPrefix(rest, "+")
+	}
+	return actions, nil
+}
+
+// getFinderCommand replaces fzf placeholders {}, {n} and {q} in the command with quoted values
+func getFinderCommand(command string, item string, query string) string {
+	return finderPlaceholderRegexp.ReplaceAllStringFunc(command, func(placeholder string) string {
+		if strings.HasPrefix(placeholder, "\\") {
+			return placeholder[1:]
+		}
+		field := placeholder[1 : len(placeholder)-1]
+		switch field {
+		case "":
+			return quoteArgument(strings.TrimSpace(item))
+		case "q":
+			return quoteArgument(query)
+		}
+		index, err := strconv.Atoi(field)
+		if err != nil {
+			return placeholder
+		}
+		fields := strings.Fields(item)
+		if index < 1 || index > len(fields) {
+			return quoteArgument("")
+		}
+		return quoteArgument(fields[index-1])
+	})
+}
+
+// isExitError returns true if the command runs but exits with a non-zero status
+func isExitError(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr)
+}
+
+// runBuiltinFinder runs the builtin fuzzy finder on the terminal in the same way as fzf with the args.
+// Only some options of fzf are supported, and the others are ignored, but unsupported key bindings are errors.
+func runBuiltinFinder(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+	options, err := parseFinderOptions(args)
+	if err != nil {
+		return nil, err
+	}
+	lines, err := readFinderLines(ioIn)
+	if err != nil {
+		return nil, err
+	}
+	finder := newBuiltinFinder(options, lines)
+	if options.filter {
+		return finder.outputMatches()
+	}
+	if options.exitZero && len(finder.matches) == 0 {
+		return nil, errorFinderNoMatch
+	}
+	if options.selectOne && len(finder.matches) == 1 {
+		return finder.output(""), nil
+	}
+
+	screen, err := newFinderScreen()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open the terminal: %w", err)
+	}
+	if err := screen.Init(); err != nil {
+		return nil, fmt.Errorf("failed to open the terminal: %w", err)
+	}
+	defer screen.Fini()
+	return finder.run(ctx, screen)
+}
+
+func readFinderLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read candidates: %w", err)
+	}
+	return lines, nil
+}
+
+// finderPreviewEvent is posted when the preview command finishes
+type finderPreviewEvent struct {
+	tcell.EventTime
+	command string
+	lines   []string
+}
+
+// finderReloadEvent is posted when the reload command finishes
+type finderReloadEvent struct {
+	tcell.EventTime
+	lines []string
+	err   error
+}
+
+// finderActionEvent is posted when actions are sent to the listen server
+type finderActionEvent struct {
+	tcell.EventTime
+	actions []finderAction
+}
+
+// builtinFinder is a fuzzy finder running on the terminal by tcell
+type builtinFinder struct {
+	options finderOptions
+	headers []string
+	items   []string
+	query   []rune
+	// matches are indexes of items matching the query
+	matches []int
+	// cursor is the index of matches on the cursor
+	cursor int
+	// offset is the index of matches on the top of the list
+	offset int
+	// selected are indexes of selected items in the order of selections
+	selected []int
+
+	preview []string
+	// previewCommand is the command of the preview with the item on the cursor
+	previewCommand string
+	previewLabel   string
+	previewHidden  bool
+	// previewOffset is the index of preview lines on the top of the preview
+	previewOffset int
+	cancelPreview context.CancelFunc
+	// port is the port of the listen server, which is exported to commands as FZF_PORT
+	port int
+	// actionErr is the error of the last action shown on the info, like a failed reload
+	actionErr error
+}
+
+func newBuiltinFinder(options finderOptions, lines []string) *builtinFinder {
+	f := &builtinFinder{
+		options: options,
+		query:   []rune(options.query),
+	}
+	f.setLines(lines)
+	return f
+}
+
+// setLines sets candidates and header lines of them
+func (f *builtinFinder) setLines(lines []string) {
+	f.headers = nil
+	if f.options.header != "" {
+		f.headers = strings.Split(strings.TrimRight(f.options.header, "\n"), "\n")
+	}
+	headerLines := f.options.headerLines
+	if headerLines > len(lines) {
+		headerLines = len(lines)
+	}
+	f.headers = append(f.headers, lines[:headerLines]...)
+	f.items = lines[headerLines:]
+	f.filter()
+}
+
+// run shows the finder until an item is selected, and returns the output of fzf.
+// It returns errorFinderCanceled if it's canceled, and errorFinderNoMatch if no items match the query.
+func (f *builtinFinder) run(ctx context.Context, screen tcell.Screen) ([]byte, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	defer func() {
+		if f.cancelPreview != nil {
+			f.cancelPreview()
+		}
+	}()
+	go func() {
+		<-ctx.Done()
+		_ = screen.PostEvent(tcell.NewEventInterrupt(nil))
+	}()
+	if f.options.listen != "" {
+		server, err := f.listen(screen)
+		if err != nil {
+			return nil, err
+		}
+		defer server.Close()
+	}
+	if actions, ok := f.options.bindings["start"]; ok {
+		f.runActions(ctx, screen, actions)
+	}
+
+	for {
+		f.updatePreview(ctx, screen)
+		f.draw(screen)
+
+		switch ev := screen.PollEvent().(type) {
+		case nil:
+			return nil, errorFinderCanceled
+		case *tcell.EventInterrupt:
+			if ctx.Err() != nil {
+				return nil, errorFinderCanceled
+			}
+		case *tcell.EventResize:
+			screen.Sync()
+		case *finderPreviewEvent:
+			if ev.command == f.previewCommand {
+				f.preview = ev.lines
+			}
+		case *finderReloadEvent:
+			f.actionErr = ev.err
+			if ev.err == nil {
+				f.reload(ev.lines)
+			}
+		case *finderActionEvent:
+			f.runActions(ctx, screen, ev.actions)
+		case *tcell.EventKey:
+			key := getFinderKeyName(ev)
+			for _, expect := range f.options.expect {
+				if key == expect {
+					return f.output(key), nil
+				}
+			}
+			if actions, ok := f.options.bindings[key]; ok {
+				f.runActions(ctx, screen, actions)
+				continue
+			}
+			switch key {
+			case "enter":
+				if len(f.matches) == 0 {
+					return nil, errorFinderNoMatch
+				}
+				return f.output(""), nil
+			case "esc", "ctrl-c", "ctrl-g", "ctrl-q":
+				return nil, errorFinderCanceled
+			}
+			f.handleKey(ev, screen)
+		}
+	}
+}
+
+// outputMatches returns all items matching the query in the same format as fzf --filter
+func (f *builtinFinder) outputMatches() ([]byte, error) {
+	if len(f.matches) == 0 {
+		return nil, errorFinderNoMatch
+	}
+	var out strings.Builder
+	for _, index := range f.matches {
+		out.WriteString(f.items[index] + "\n")
+	}
+	return []byte(out.String()), nil
+}
+
+// output returns the output in the same format as fzf
+func (f *builtinFinder) output(key string) []byte {
+	var out strings.Builder
+	if len(f.options.expect) > 0 {
+		out.WriteString(key + "\n")
+	}
+	selected := f.selected
+	if len(selected) == 0 && f.cursor < len(f.matches) {
+		selected = []int{f.matches[f.cursor]}
+	}
+	for _, index := range selected {
+		out.WriteString(f.items[index] + "\n")
+	}
+	return []byte(out.String())
+}
+
+func (f *builtinFinder) handleKey(ev *tcell.EventKey, screen tcell.Screen) {
+	_, height := screen.Size()
+	switch ev.Key() {
+	case tcell.KeyUp, tcell.KeyCtrlP, tcell.KeyCtrlK:
+		f.moveCursor(-1)
+	case tcell.KeyDown, tcell.KeyCtrlN, tcell.KeyCtrlJ:
+		f.moveCursor(1)
+	case tcell.KeyPgUp:
+		f.moveCursor(-height / 2)
+	case tcell.KeyPgDn:
+		f.moveCursor(height / 2)
+	case tcell.KeyTab:
+		f.toggle()
+		f.moveCursor(1)
+	case tcell.KeyBacktab:
+		f.toggle()
+		f.moveCursor(-1)
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		if len(f.query) > 0 {
+			f.query = f.query[:len(f.query)-1]
+			f.filter()
+		}
+	case tcell.KeyCtrlU:
+		f.query = nil
+		f.filter()
+	case tcell.KeyCtrlW:
+		query := strings.TrimRightFunc(string(f.query), unicode.IsSpace)
+		index := strings.LastIndexFunc(query, unicode.IsSpace)
+		f.query = []rune(query[:index+1])
+		f.filter()
+	case tcell.KeyRune:
+		if ev.Modifiers()&(tcell.ModAlt|tcell.ModCtrl) != 0 {
+			return
+		}
+		f.query = append(f.query, ev.Rune())
+		f.filter()
+	}
+}
+
+func (f *builtinFinder) moveCursor(delta int) {
+	f.cursor += delta
+	if f.cursor >= len(f.matches) {
+		f.cursor = len(f.matches) - 1
+	}
+	if f.cursor < 0 {
+		f.cursor = 0
+	}
+}
+
+// toggle selects or deselects the item on the cursor if multiple items can be selected
+func (f *builtinFinder) toggle() {
+	if !f.options.multi || f.cursor >= len(f.matches) {
+		return
+	}
+	item := f.matches[f.cursor]
+	for i, index := range f.selected {
+		if index == item {
+			f.selected = append(f.selected[:i], f.selected[i+1:]...)
+			return
+		}
+	}
+	f.selected = append(f.selected, item)
+}
+
+// filter finds items matching the query, keeping the order of items
+func (f *builtinFinder) filter() {
+	terms := parseFinderQuery(string(f.query))
+	f.matches = f.matches[:0]
+	for i, item := range f.items {
+		if matchFinderTerms(terms, item) {
+			f.matches = append(f.matches, i)
+		}
+	}
+	f.cursor = 0
+	f.offset = 0
+}
+
+// finderTerm is a term of the query in the extended search mode of fzf
+type finderTerm struct {
+	text          string
+	exact         bool
+	prefix        bool
+	suffix        bool
+	inverse       bool
+	caseSensitive bool
+}
+
+// parseFinderQuery parses the query separated by spaces, with 'exact, ^prefix, suffix$ and !inverse terms
+func parseFinderQuery(query string) []finderTerm {
+	var terms []finderTerm
+	for _, text := range strings.Fields(query) {
+		var term finderTerm
+		if strings.HasPrefix(text, "!") {
+			term.inverse = true
+			term.exact = true
+			text = text[1:]
+		}
+		if strings.HasPrefix(text, "'") {
+			term.exact = true
+			text = text[1:]
+		}
+		if strings.HasPrefix(text, "^") {
+			term.prefix = true
+			text = text[1:]
+		}
+		if strings.HasSuffix(text, "$") {
+			term.suffix = true
+			text = text[:len(text)-1]
+		}
+		if text == "" {
+			continue
+		}
+		// Smart case like fzf
+		term.caseSensitive = strings.ToLower(text) != text
+		term.text = text
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+func matchFinderTerms(terms []finderTerm, item string) bool {
+	for _, term := range terms {
+		if matchFinderTerm(term, item) == term.inverse {
+			return false
+		}
+	}
+	return true
+}
+
+func matchFinderTerm(term finderTerm, item string) bool {
+	if !term.caseSensitive {
+		item = strings.ToLower(item)
+	}
+	switch {
+	case term.prefix && term.suffix:
+		return strings.TrimSpace(item) == term.text
+	case term.prefix:
+		return strings.HasPrefix(strings.TrimSpace(item), term.text)
+	case term.suffix:
+		return strings.HasSuffix(strings.TrimSpace(item), term.text)
+	case term.exact:
+		return strings.Contains(item, term.text)
+	}
+	// Fuzzy match if all characters appear in order
+	pattern := []rune(term.text)
+	for _, r := range item {
+		if len(pattern) == 0 {
+			break
+		}
+		if r == pattern[0] {
+			pattern = pattern[1:]
+		}
+	}
+	return len(pattern) == 0
+}
+
+// currentItem returns the item on the cursor, or an empty string if no items match the query
+func (f *builtinFinder) currentItem() string {
+	if f.cursor < len(f.matches) {
+		return f.items[f.matches[f.cursor]]
+	}
+	return ""
+}
+
+// newCommand returns the command with placeholders replaced by the item on the cursor and the query.
+// Environment variables are exported in the same way as fzf.
+func (f *builtinFinder) newCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "sh", "-c", getFinderCommand(command, f.currentItem(), string(f.query)))
+	cmd.Env = append(os.Environ(), "FZF_QUERY="+string(f.query), "FZF_PREVIEW_LABEL="+f.previewLabel)
+	if f.port > 0 {
+		cmd.Env = append(cmd.Env, "FZF_PORT="+strconv.Itoa(f.port))
+	}
+	return cmd
+}
+
+// updatePreview runs the preview command if the item on the cursor or the command is changed
+func (f *builtinFinder) updatePreview(ctx context.Context, screen tcell.Screen) {
+	if f.options.preview == "" || f.previewHidden {
+		return
+	}
+	item := f.currentItem()
+	command := ""
+	if item != "" {
+		command = getFinderCommand(f.options.preview, item, string(f.query))
+	}
+	if command == f.previewCommand && f.preview != nil {
+		return
+	}
+	if f.cancelPreview != nil {
+		f.cancelPreview()
+	}
+	f.previewCommand = command
+	f.preview = []string{}
+	f.previewOffset = 0
+	if item == "" {
+		return
+	}
+
+	previewCtx, cancel := context.WithCancel(ctx)
+	f.cancelPreview = cancel
+	cmd := f.newCommand(previewCtx, f.options.preview)
+	go func() {
+		out, _ := cmd.CombinedOutput()
+		if previewCtx.Err() != nil {
+			return
+		}
+		ev := &finderPreviewEvent{
+			command: command,
+			lines:   strings.Split(strings.TrimRight(string(out), "\n"), "\n"),
+		}
+		ev.SetEventNow()
+		_ = screen.PostEvent(ev)
+	}()
+}
+
+// runActions runs actions of a key binding or the listen server in order.
+// If an action fails, the error is shown on the info and the rest of actions are not run.
+func (f *builtinFinder) runActions(ctx context.Context, screen tcell.Screen, actions []finderAction) {
+	f.actionErr = nil
+	for _, action := range actions {
+		if err := f.runAction(ctx, screen, action); err != nil {
+			f.actionErr = err
+			return
+		}
+	}
+}
+
+func (f *builtinFinder) runAction(ctx context.Context, screen tcell.Screen, action finderAction) error {
+	_, height := screen.Size()
+	previewHeight := height * finderPreviewRatio / 100
+	switch action.name {
+	case "reload":
+		f.startReload(ctx, screen, action.argument)
+	case "execute":
+		return f.execute(ctx, screen, action.argument)
+	case "execute-silent":
+		// The exit status is ignored like fzf
+		if err := f.newCommand(ctx, action.argument).Run(); err != nil && !isExitError(err) {
+			return fmt.Errorf("failed to run %s: %w", action.argument, err)
+		}
+	case "transform":
+		out, err := f.newCommand(ctx, action.argument).Output()
+		if err != nil {
+			return fmt.Errorf("failed to transform by %s: %w", action.argument, err)
+		}
+		actions, err := parseFinderActions(strings.TrimSpace(string(out)))
+		if err != nil {
+			return fmt.Errorf("the builtin finder does not support the actions by %s: %w", action.argument, err)
+		}
+		for _, action := range actions {
+			if err := f.runAction(ctx, screen, action); err != nil {
+				return err
+			}
+		}
+	case "change-preview":
+		f.options.preview = action.argument
+		f.preview = nil
+	case "change-preview-label":
+		f.previewLabel = action.argument
+	case "toggle-preview":
+		f.previewHidden = !f.previewHidden
+	case "preview-up":
+		f.scrollPreview(-1)
+	case "preview-down":
+		f.scrollPreview(1)
+	case "preview-page-up":
+		f.scrollPreview(-previewHeight)
+	case "preview-page-down":
+		f.scrollPreview(previewHeight)
+	case "kill-line":
+		// Nothing is deleted, because the query is always edited at the end
+	}
+	return nil
+}
+
+// execute runs the command on the terminal while the finder is suspended
+func (f *builtinFinder) execute(ctx context.Context, screen tcell.Screen, command string) error {
+	terminal, err := openFinderTerminal()
+	if err != nil {
+		return fmt.Errorf("failed to open the terminal: %w", err)
+	}
+	defer terminal.Close()
+	cmd := f.newCommand(ctx, command)
+	cmd.Stdin = terminal
+	cmd.Stdout = terminal
+	cmd.Stderr = terminal
+
+	if err := screen.Suspend(); err != nil {
+		return fmt.Errorf("failed to suspend the finder: %w", err)
+	}
+	runErr := cmd.Run()
+	if err := screen.Resume(); err != nil {
+		return fmt.Errorf("failed to resume the finder: %w", err)
+	}
+	// The exit status is ignored like fzf
+	if runErr != nil && !isExitError(runErr) {
+		return fmt.Errorf("failed to run %s: %w", command, runErr)
+	}
+	return nil
+}
+
+func (f *builtinFinder) scrollPreview(delta int) {
+	f.previewOffset += delta
+	if f.previewOffset >= len(f.preview) {
+		f.previewOffset = len(f.preview) - 1
+	}
+	if f.previewOffset < 0 {
+		f.previewOffset = 0
+	}
+}
+
+// startReload runs the command to reload candidates in the background
+func (f *builtinFinder) startReload(ctx context.Context, screen tcell.Screen, command string) {
+	cmd := f.newCommand(ctx, command)
+	go func() {
+		out, err := cmd.Output()
+		if ctx.Err() != nil {
+			return
+		}
+		ev := &finderReloadEvent{}
+		if err != nil {
+			ev.err = fmt.Errorf("failed to reload: %w", err)
+		} else {
+			ev.lines, ev.err = readFinderLines(strings.NewReader(string(out)))
+		}
+		ev.SetEventNow()
+		_ = screen.PostEvent(ev)
+	}()
+}
+
+// reload replaces candidates, keeping the cursor and selections by ids of items
+func (f *builtinFinder) reload(lines []string) {
+	var cursorID string
+	if f.cursor < len(f.matches) {
+		cursorID = f.itemID(f.items[f.matches[f.cursor]])
+	}
+	selectedIDs := make([]string, len(f.selected))
+	for i, index := range f.selected {
+		selectedIDs[i] = f.itemID(f.items[index])
+	}
+
+	f.setLines(lines)
+	f.selected = nil
+	for _, id := range selectedIDs {
+		for i, item := range f.items {
+			if f.itemID(item) == id {
+				f.selected = append(f.selected, i)
+				break
+			}
+		}
+	}
+	for i, index := range f.matches {
+		if f.itemID(f.items[index]) == cursorID {
+			f.cursor = i
+			break
+		}
+	}
+}
+
+func (f *builtinFinder) itemID(item string) string {
+	if len(f.options.idFields) == 0 {
+		return item
+	}
+	fields := strings.Fields(item)
+	ids := make([]string, 0, len(f.options.idFields))
+	for _, index := range f.options.idFields {
+		if index <= len(fields) {
+			ids = append(ids, fields[index-1])
+		}
+	}
+	return strings.Join(ids, " ")
+}
+
+// listen accepts actions like fzf --listen, and the actions are run by the event loop.
+// Requests need the API key of FZF_API_KEY if it's set, and actions running commands need --listen-unsafe.
+func (f *builtinFinder) listen(screen tcell.Screen) (*http.Server, error) {
+	address := f.options.listen
+	if !strings.Contains(address, ":") {
+		address = "localhost:" + address
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	f.port = listener.Addr().(*net.TCPAddr).Port
+	apiKey := os.Getenv(envNameFzfAPIKey)
+	listenUnsafe := f.options.listenUnsafe
+	server := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiKey != "" && r.Header.Get("x-api-key") != apiKey {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			actions, err := parseFinderActions(strings.TrimSpace(string(body)))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for _, action := range actions {
+				if finderCommandActions[action.name] && !listenUnsafe {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+			}
+			ev := &finderActionEvent{actions: actions}
+			ev.SetEventNow()
+			if err := screen.PostEvent(ev); err != nil {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		}),
+	}
+	go func() {
+		_ = server.Serve(listener)
+	}()
+	return server, nil
+}
+
+// draw shows the query, headers, items and the preview from the top of the screen like fzf --layout reverse
+func (f *builtinFinder) draw(screen tcell.Screen) {
+	screen.Clear()
+	width, height := screen.Size()
+	listHeight := height
+	if f.options.preview != "" && !f.previewHidden && height >= 10 {
+		listHeight = height - height*finderPreviewRatio/100
+	}
+
+	prompt := finderPrompt + string(f.query)
+	drawFinderText(screen, 0, 0, width, prompt, tcell.StyleDefault)
+	screen.ShowCursor(runewidth.StringWidth(prompt), 0)
+	info := fmt.Sprintf("%d/%d", len(f.matches), len(f.items))
+	if len(f.selected) > 0 {
+		info = fmt.Sprintf("%s (%d)", info, len(f.selected))
+	}
+	if f.actionErr != nil {
+		info = info + " " + f.actionErr.Error()
+	}
+	infoX := runewidth.StringWidth(prompt) + 2
+	drawFinderText(screen, infoX, 0, width-infoX, info, tcell.StyleDefault.Dim(true))
+
+	row := 1
+	for _, header := range f.headers {
+		if row >= listHeight {
+			break
+		}
+		drawFinderText(screen, 2, row, width-2, header, tcell.StyleDefault.Bold(true))
+		row++
+	}
+
+	rows := listHeight - row
+	if f.cursor < f.offset {
+		f.offset = f.cursor
+	}
+	if rows > 0 && f.cursor >= f.offset+rows {
+		f.offset = f.cursor - rows + 1
+	}
+	for i := f.offset; i < len(f.matches) && row < listHeight; i++ {
+		index := f.matches[i]
+		style := tcell.StyleDefault
+		prefix := "  "
+		if i == f.cursor {
+			style = style.Reverse(true)
+			prefix = ">" + prefix[1:]
+		}
+		for _, selected := range f.selected {
+			if selected == index {
+				prefix = prefix[:1] + ">"
+				break
+			}
+		}
+		drawFinderText(screen, 0, row, 2, prefix, style)
+		drawFinderText(screen, 2, row, width-2, f.items[index], style)
+		row++
+	}
+
+	if listHeight < height {
+		drawFinderText(screen, 0, listHeight, width, strings.Repeat("─", width), tcell.StyleDefault.Dim(true))
+		if f.previewLabel != "" {
+			drawFinderText(screen, 2, listHeight, width-2, " "+f.previewLabel+" ", tcell.StyleDefault)
+		}
+		var preview []string
+		if f.previewOffset < len(f.preview) {
+			preview = f.preview[f.previewOffset:]
+		}
+		for i, line := range preview {
+			if listHeight+1+i >= height {
+				break
+			}
+			drawFinderText(screen, 0, listHeight+1+i, width, line, tcell.StyleDefault)
+		}
+	}
+	screen.Show()
+}
+
+// drawFinderText draws the text on the row within the width, expanding tabs
+func drawFinderText(screen tcell.Screen, x int, y int, width int, text string, style tcell.Style) {
+	column := 0
+	for _, r := range text {
+		if r == '\t' {
+			for {
+				if column >= width {
+					return
+				}
+				screen.SetContent(x+column, y, ' ', nil, style)
+				column++
+				if column%8 == 0 {
+					break
+				}
+			}
+			continue
+		}
+		w := runewidth.RuneWidth(r)
+		if w == 0 {
+			continue
+		}
+		if column+w > width {
+			return
+		}
+		screen.SetContent(x+column, y, r, nil, style)
+		column += w
+	}
+}
+
+// getFinderKeyName returns the name of the key in the same way as fzf, like ctrl-o or alt-a
+func getFinderKeyName(ev *tcell.EventKey) string {
+	switch ev.Key() {
+	case tcell.KeyEnter:
+		return "enter"
+	case tcell.KeyEsc:
+		return "esc"
+	case tcell.KeyTab:
+		return "tab"
+	case tcell.KeyBacktab:
+		return "btab"
+	case tcell.KeyBackspace, tcell.KeyBackspace2:
+		return "bspace"
+	case tcell.KeyUp:
+		return "up"
+	case tcell.KeyDown:
+		return "down"
+	case tcell.KeyLeft:
+		return "left"
+	case tcell.KeyRight:
+		return "right"
+	case tcell.KeyRune:
+		if ev.Modifiers()&tcell.ModAlt != 0 {
+			return "alt-" + string(ev.Rune())
+		}
+		return string(ev.Rune())
+	}
+	if ev.Key() >= tcell.KeyCtrlA && ev.Key() <= tcell.KeyCtrlZ {
+		return "ctrl-" + string(rune('a'+ev.Key()-tcell.KeyCtrlA))
+	}
+	if ev.Key() >= tcell.KeyF1 && ev.Key() <= tcell.KeyF12 {
+		return "f" + strconv.Itoa(int(ev.Key()-tcell.KeyF1)+1)
+	}
+	return ev.Name()
+}
@@ -0,0 +1,600 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// simulationFinderScreen injects keys after the screen is initialized
+type simulationFinderScreen struct {
+	tcell.SimulationScreen
+	keys []*tcell.EventKey
+}
+
+func (s simulationFinderScreen) Init() error {
+	if err := s.SimulationScreen.Init(); err != nil {
+		return err
+	}
+	go func() {
+		for _, key := range s.keys {
+			s.InjectKey(key.Key(), key.Rune(), key.Modifiers())
+		}
+	}()
+	return nil
+}
+
+func finderKeys(keys ...interface{}) []*tcell.EventKey {
+	var events []*tcell.EventKey
+	for _, key := range keys {
+		switch key := key.(type) {
+		case string:
+			for _, r := range key {
+				events = append(events, tcell.NewEventKey(tcell.KeyRune, r, tcell.ModNone))
+			}
+		case tcell.Key:
+			events = append(events, tcell.NewEventKey(key, 0, tcell.ModNone))
+		}
+	}
+	return events
+}
+
+func TestParseFinderOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		args    []string
+		want    finderOptions
+		wantErr error
+	}{
+		{
+			name: "default options",
+			args: []string{
+				"--inline-info", "--multi", "--layout", "reverse",
+				"--preview", "kubectl describe pods {1}", "--preview-window", "down:70%",
+				"--bind", "ctrl-k:kill-line,ctrl-alt-t:toggle-preview",
+				"--header-lines", "1",
+			},
+			want: finderOptions{
+				multi:       true,
+				headerLines: 1,
+				preview:     "kubectl describe pods {1}",
+				bindings: map[string][]finderAction{
+					"ctrl-k":     {{name: "kill-line"}},
+					"ctrl-alt-t": {{name: "toggle-preview"}},
+				},
+			},
+		},
+		{
+			name: "options for the navigation and the watch mode",
+			args: []string{
+				"--no-multi", "--query=pod", "--expect", "ctrl-o,ctrl-b", "--header", "ctrl-o: owned objects",
+				"--bind", "ctrl-r:reload:kubectl get pods,svc --no-headers=true",
+				"--listen", "127.0.0.1:10000", "--id-nth", "1,2",
+			},
+			want: finderOptions{
+				query:  "pod",
+				header: "ctrl-o: owned objects",
+				expect: []string{"ctrl-o", "ctrl-b"},
+				bindings: map[string][]finderAction{
+					"ctrl-r": {{name: "reload", argument: "kubectl get pods,svc --no-headers=true"}},
+				},
+				listen:   "127.0.0.1:10000",
+				idFields: []int{1, 2},
+			},
+		},
+		{
+			name: "options for the watch mode on a port chosen by the finder",
+			args: []string{
+				"--listen-unsafe=localhost:0",
+				"--bind", "start:execute-silent(echo $FZF_PORT > /tmp/port)",
+			},
+			want: finderOptions{
+				bindings: map[string][]finderAction{
+					"start": {{name: "execute-silent", argument: "echo $FZF_PORT > /tmp/port"}},
+				},
+				listen:       "localhost:0",
+				listenUnsafe: true,
+			},
+		},
+		{
+			name: "reload in parentheses",
+			args: []string{"--bind", "ctrl-x:reload(kubectl get pods),ctrl-k:kill-line"},
+			want: finderOptions{
+				bindings: map[string][]finderAction{
+					"ctrl-x": {{name: "reload", argument: "kubectl get pods"}},
+					"ctrl-k": {{name: "kill-line"}},
+				},
+			},
+		},
+		{
+			name:    "unsupported action",
+			args:    []string{"--bind", "ctrl-a:toggle-all"},
+			wantErr: errors.New("the builtin finder does not support the binding ctrl-a:toggle-all: unsupported action toggle-all"),
+		},
+		{
+			name:    "unsupported event",
+			args:    []string{"--bind", "focus:transform-header:echo {}"},
+			wantErr: errors.New("the builtin finder does not support the binding focus:transform-header:echo {}: unsupported event focus"),
+		},
+		{
+			name:    "invalid header lines",
+			args:    []string{"--header-lines", "one"},
+			wantErr: errors.New("fzf option --header-lines must be a number: one"),
+		},
+		{
+			name:    "no value",
+			args:    []string{"--preview"},
+			wantErr: errors.New("fzf option --preview needs a value"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := parseFinderOptions(tc.args)
+			assert.Equal(t, tc.want, got)
+			if tc.wantErr == nil {
+				assert.NoError(t, gotErr)
+			} else {
+				assert.EqualError(t, gotErr, tc.wantErr.Error())
+			}
+		})
+	}
+}
+
+func TestParseFinderActions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		value   string
+		want    []finderAction
+		wantErr error
+	}{
+		{
+			name:  "actions chained by +",
+			value: `execute(printf 'Delete %s? [y/N] ' {1})+reload[kubectl get pods -l 'env in (dev)']+toggle-preview`,
+			want: []finderAction{
+				{name: "execute", argument: "printf 'Delete %s? [y/N] ' {1}"},
+				{name: "reload", argument: "kubectl get pods -l 'env in (dev)'"},
+				{name: "toggle-preview"},
+			},
+		},
+		{
+			name:  "the last action takes the rest after a colon",
+			value: "change-preview-label(yaml)+change-preview:kubectl get pods {1} -o=yaml",
+			want: []finderAction{
+				{name: "change-preview-label", argument: "yaml"},
+				{name: "change-preview", argument: "kubectl get pods {1} -o=yaml"},
+			},
+		},
+		{
+			name:    "unsupported action with an argument",
+			value:   "reload(kubectl get pods)+transform-query(echo pod)",
+			wantErr: errors.New("unsupported action transform-query"),
+		},
+		{
+			name:    "argument is required",
+			value:   "reload",
+			wantErr: errors.New("unsupported action reload"),
+		},
+		{
+			name:    "unterminated argument",
+			value:   "reload(kubectl get pods",
+			wantErr: errors.New("invalid action reload(kubectl get pods"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := parseFinderActions(tc.value)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+func TestGetFinderCommand(t *testing.T) {
+	testCases := []struct {
+		name    string
+		command string
+		item    string
+		query   string
+		want    string
+	}{
+		{
+			name:    "fields",
+			command: "kubectl describe pods {2} -n={1}",
+			item:    "default   pod1   1/1   Running",
+			want:    "kubectl describe pods pod1 -n=default",
+		},
+		{
+			name:    "whole line and query",
+			command: "echo {} {q}",
+			item:    "pod1   1/1 ",
+			query:   "it's",
+			want:    `echo 'pod1   1/1' 'it'\''s'`,
+		},
+		{
+			name:    "out of fields",
+			command: "kubectl logs {3}",
+			item:    "pod1",
+			want:    "kubectl logs ''",
+		},
+		{
+			name:    "escaped placeholders",
+			command: `printf %s 'change-preview:kubectl describe pods \{1}' {1}`,
+			item:    "pod1",
+			want:    `printf %s 'change-preview:kubectl describe pods {1}' pod1`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, getFinderCommand(tc.command, tc.item, tc.query))
+		})
+	}
+}
+
+func TestMatchFinderTerms(t *testing.T) {
+	item := "api-server-7d9f   1/1   Running"
+	testCases := []struct {
+		query string
+		want  bool
+	}{
+		{query: "", want: true},
+		{query: "apisrv", want: true},
+		{query: "srvapi", want: false},
+		{query: "API", want: false},
+		{query: "api run", want: true},
+		{query: "'server-7", want: true},
+		{query: "'srv", want: false},
+		{query: "^api", want: true},
+		{query: "^server", want: false},
+		{query: "Running$", want: true},
+		{query: "!Pending", want: true},
+		{query: "!Running", want: false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.query, func(t *testing.T) {
+			assert.Equal(t, tc.want, matchFinderTerms(parseFinderQuery(tc.query), item))
+		})
+	}
+}
+
+func TestRunBuiltinFinder(t *testing.T) {
+	backupNewFinderScreen := newFinderScreen
+	defer func() {
+		newFinderScreen = backupNewFinderScreen
+	}()
+
+	input := "NAME READY STATUS\npod1 1/1 Running\npod2 0/1 Pending\napi 1/1 Running\n"
+	testCases := []struct {
+		name    string
+		args    []string
+		keys    []*tcell.EventKey
+		want    string
+		wantErr error
+	}{
+		{
+			name: "select the 1st item",
+			args: []string{"--header-lines", "1"},
+			keys: finderKeys(tcell.KeyEnter),
+			want: "pod1 1/1 Running\n",
+		},
+		{
+			name: "filter items by the query",
+			args: []string{"--header-lines", "1", "--query", "pod"},
+			keys: finderKeys("2", tcell.KeyEnter),
+			want: "pod2 0/1 Pending\n",
+		},
+		{
+			name: "move the cursor",
+			args: []string{"--header-lines", "1"},
+			keys: finderKeys(tcell.KeyDown, tcell.KeyDown, tcell.KeyUp, tcell.KeyEnter),
+			want: "pod2 0/1 Pending\n",
+		},
+		{
+			name: "select multiple items",
+			args: []string{"--multi", "--header-lines", "1"},
+			keys: finderKeys(tcell.KeyDown, tcell.KeyDown, tcell.KeyTab, tcell.KeyUp, tcell.KeyUp, tcell.KeyTab, tcell.KeyEnter),
+			want: "api 1/1 Running\npod1 1/1 Running\n",
+		},
+		{
+			name: "multiple items cannot be selected without --multi",
+			args: []string{"--no-multi", "--header-lines", "1"},
+			keys: finderKeys(tcell.KeyTab, tcell.KeyEnter),
+			want: "pod2 0/1 Pending\n",
+		},
+		{
+			name: "expected key",
+			args: []string{"--header-lines", "1", "--expect", "ctrl-o,ctrl-b"},
+			keys: finderKeys(tcell.KeyCtrlO),
+			want: "ctrl-o\npod1 1/1 Running\n",
+		},
+		{
+			name: "enter with expected keys",
+			args: []string{"--header-lines", "1", "--expect", "ctrl-o"},
+			keys: finderKeys(tcell.KeyEnter),
+			want: "\npod1 1/1 Running\n",
+		},
+		{
+			name:    "no matches",
+			args:    []string{"--header-lines", "1"},
+			keys:    finderKeys("xyz", tcell.KeyEnter),
+			wantErr: errorFinderNoMatch,
+		},
+		{
+			name: "filter",
+			args: []string{"--header-lines", "1", "--filter", "Running"},
+			want: "pod1 1/1 Running\napi 1/1 Running\n",
+		},
+		{
+			name:    "filter without matches",
+			args:    []string{"--header-lines", "1", "-f", "xyz"},
+			wantErr: errorFinderNoMatch,
+		},
+		{
+			name: "select one",
+			args: []string{"--header-lines", "1", "--query", "api", "--select-1"},
+			want: "api 1/1 Running\n",
+		},
+		{
+			name: "select one with multiple matches",
+			args: []string{"--header-lines", "1", "--query", "pod", "-1"},
+			keys: finderKeys(tcell.KeyDown, tcell.KeyEnter),
+			want: "pod2 0/1 Pending\n",
+		},
+		{
+			name:    "exit zero",
+			args:    []string{"--header-lines", "1", "--query", "xyz", "--exit-0"},
+			wantErr: errorFinderNoMatch,
+		},
+		{
+			name:    "canceled",
+			args:    []string{"--header-lines", "1"},
+			keys:    finderKeys("pod", tcell.KeyEsc),
+			wantErr: errorFinderCanceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			newFinderScreen = func() (tcell.Screen, error) {
+				return simulationFinderScreen{
+					SimulationScreen: tcell.NewSimulationScreen(""),
+					keys:             tc.keys,
+				}, nil
+			}
+			got, gotErr := runBuiltinFinder(context.Background(), tc.args, strings.NewReader(input), io.Discard)
+			assert.Equal(t, tc.want, string(got))
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+func TestBuiltinFinder_draw(t *testing.T) {
+	screen := tcell.NewSimulationScreen("")
+	require.NoError(t, screen.Init())
+	defer screen.Fini()
+	screen.SetSize(40, 6)
+
+	sut := newBuiltinFinder(finderOptions{
+		multi:       true,
+		header:      "ctrl-o: owned",
+		headerLines: 1,
+	}, []string{"NAME\tREADY", "pod1\t1/1", "pod2\t0/1", "pod3\t1/1"})
+	sut.query = []rune("pod")
+	sut.filter()
+	sut.toggle()
+	sut.moveCursor(1)
+	sut.draw(screen)
+
+	cells, width, height := screen.GetContents()
+	var got []string
+	for y := 0; y < height; y++ {
+		var line []rune
+		for x := 0; x < width; x++ {
+			line = append(line, cells[y*width+x].Runes...)
+		}
+		got = append(got, strings.TrimRight(string(line), " "))
+	}
+	assert.Equal(t, []string{
+		"> pod  3/3 (1)",
+		"  ctrl-o: owned",
+		"  NAME    READY",
+		" >pod1    1/1",
+		"> pod2    0/1",
+		"  pod3    1/1",
+	}, got)
+}
+
+func TestBuiltinFinder_reload(t *testing.T) {
+	sut := newBuiltinFinder(finderOptions{
+		multi:       true,
+		headerLines: 1,
+		idFields:    []int{1},
+	}, []string{"NAME STATUS", "pod1 Pending", "pod2 Pending", "pod3 Pending"})
+	sut.moveCursor(1)
+	sut.toggle()
+	sut.moveCursor(1)
+
+	sut.reload([]string{"NAME STATUS", "pod0 Pending", "pod3 Running", "pod2 Running"})
+	assert.Equal(t, []string{"NAME STATUS"}, sut.headers)
+	assert.Equal(t, []string{"pod0 Pending", "pod3 Running", "pod2 Running"}, sut.items)
+	assert.Equal(t, []int{2}, sut.selected)
+	assert.Equal(t, 1, sut.cursor)
+}
+
+func TestBuiltinFinder_runActions(t *testing.T) {
+	backupOpenFinderTerminal := openFinderTerminal
+	defer func() {
+		openFinderTerminal = backupOpenFinderTerminal
+	}()
+
+	screen := tcell.NewSimulationScreen("")
+	require.NoError(t, screen.Init())
+	defer screen.Fini()
+	screen.SetSize(40, 20)
+
+	dir, err := os.MkdirTemp("", "kubectl-fzf-test-")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	terminal, err := os.Create(filepath.Join(dir, "terminal"))
+	require.NoError(t, err)
+	defer terminal.Close()
+	openFinderTerminal = func() (*os.File, error) {
+		return os.OpenFile(terminal.Name(), os.O_RDWR|os.O_APPEND, 0)
+	}
+
+	sut := newBuiltinFinder(finderOptions{
+		preview:     "echo {1}",
+		headerLines: 1,
+	}, []string{"NAME READY", "pod1 1/1", "pod2 0/1"})
+	sut.query = []rune("pod")
+	run := func(value string) {
+		actions, err := parseFinderActions(value)
+		require.NoError(t, err)
+		sut.runActions(context.Background(), screen, actions)
+		require.NoError(t, sut.actionErr)
+	}
+
+	t.Run("execute on the terminal and execute-silent", func(t *testing.T) {
+		silentFile := filepath.Join(dir, "silent")
+		run(`execute(echo {1} $FZF_QUERY)+execute-silent:echo {2} > ` + silentFile)
+		got, err := os.ReadFile(terminal.Name())
+		require.NoError(t, err)
+		assert.Equal(t, "pod1 pod\n", string(got))
+		got, err = os.ReadFile(silentFile)
+		require.NoError(t, err)
+		assert.Equal(t, "1/1\n", string(got))
+	})
+
+	t.Run("transform toggles the preview by the preview label", func(t *testing.T) {
+		toggle := `transform:[ "$FZF_PREVIEW_LABEL" = yaml ] && printf %s 'change-preview-label(describe)+change-preview:echo describe \{1}' || printf %s 'change-preview-label(yaml)+change-preview:echo yaml \{1}'`
+		run(toggle)
+		assert.Equal(t, "yaml", sut.previewLabel)
+		assert.Equal(t, "echo yaml {1}", sut.options.preview)
+		run(toggle)
+		assert.Equal(t, "describe", sut.previewLabel)
+		assert.Equal(t, "echo describe {1}", sut.options.preview)
+	})
+
+	t.Run("toggle and scroll the preview", func(t *testing.T) {
+		sut.preview = []string{"line1", "line2", "line3"}
+		run("toggle-preview+preview-down+preview-down")
+		assert.True(t, sut.previewHidden)
+		assert.Equal(t, 2, sut.previewOffset)
+		run("preview-page-up+toggle-preview")
+		assert.False(t, sut.previewHidden)
+		assert.Equal(t, 0, sut.previewOffset)
+	})
+
+	t.Run("transform outputs an unsupported action", func(t *testing.T) {
+		actions, err := parseFinderActions("transform:echo 'toggle-all'")
+		require.NoError(t, err)
+		sut.runActions(context.Background(), screen, actions)
+		assert.EqualError(t, sut.actionErr, "the builtin finder does not support the actions by echo 'toggle-all': unsupported action toggle-all")
+	})
+}
+
+func TestRunBuiltinFinder_listen(t *testing.T) {
+	backupNewFinderScreen := newFinderScreen
+	defer func() {
+		newFinderScreen = backupNewFinderScreen
+	}()
+
+	testCases := []struct {
+		name       string
+		listen     string
+		apiKey     string
+		wantStatus int
+		want       string
+	}{
+		{
+			name:       "reload by --listen-unsafe",
+			listen:     "--listen-unsafe=localhost:0",
+			apiKey:     "key",
+			wantStatus: http.StatusOK,
+			want:       "pod3 1/1 Running\n",
+		},
+		{
+			name:       "invalid API key",
+			listen:     "--listen-unsafe=localhost:0",
+			apiKey:     "invalid",
+			wantStatus: http.StatusUnauthorized,
+			want:       "pod1 1/1 Running\n",
+		},
+		{
+			name:       "reload is not accepted by --listen",
+			listen:     "--listen=localhost:0",
+			apiKey:     "key",
+			wantStatus: http.StatusForbidden,
+			want:       "pod1 1/1 Running\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			restoreEnv := setEnv(envNameFzfAPIKey, "key")
+			defer restoreEnv()
+			portFile, err := os.CreateTemp("", "kubectl-fzf-port-")
+			require.NoError(t, err)
+			require.NoError(t, portFile.Close())
+			defer os.Remove(portFile.Name())
+
+			screen := tcell.NewSimulationScreen("")
+			newFinderScreen = func() (tcell.Screen, error) {
+				return screen, nil
+			}
+			go func() {
+				// The finder exits by enter even if the request fails
+				defer screen.InjectKey(tcell.KeyEnter, 0, tcell.ModNone)
+				var port string
+				for port == "" {
+					time.Sleep(time.Millisecond)
+					out, err := os.ReadFile(portFile.Name())
+					if !assert.NoError(t, err) {
+						return
+					}
+					port = strings.TrimSpace(string(out))
+				}
+				req, err := http.NewRequest(http.MethodPost, "http://localhost:"+port, strings.NewReader(`reload:printf 'NAME READY STATUS\npod3 1/1 Running\n'`))
+				if !assert.NoError(t, err) {
+					return
+				}
+				req.Header.Set("x-api-key", tc.apiKey)
+				res, err := http.DefaultClient.Do(req)
+				if !assert.NoError(t, err) {
+					return
+				}
+				res.Body.Close()
+				assert.Equal(t, tc.wantStatus, res.StatusCode)
+
+				if res.StatusCode == http.StatusOK {
+					// Wait until the reloaded item is drawn
+					for !strings.Contains(getSimulationScreenText(screen), "pod3") {
+						time.Sleep(time.Millisecond)
+					}
+				}
+			}()
+
+			args := []string{"--header-lines", "1", tc.listen, "--bind", "start:execute-silent:echo $FZF_PORT > " + portFile.Name()}
+			got, gotErr := runBuiltinFinder(context.Background(), args, strings.NewReader("NAME READY STATUS\npod1 1/1 Running\n"), io.Discard)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+// getSimulationScreenText returns the text shown on the screen
+func getSimulationScreenText(screen tcell.SimulationScreen) string {
+	cells, _, _ := screen.GetContents()
+	var text []rune
+	for _, cell := range cells {
+		text = append(text, cell.Runes...)
+	}
+	return string(text)
+}
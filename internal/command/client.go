@@ -0,0 +1,443 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	BackendKubectl  = "kubectl"
+	BackendClientGo = "client-go"
+
+	// tableAcceptHeader requests the same columns as kubectl get
+	tableAcceptHeader = "application/json;as=Table;v=v1;g=meta.k8s.io,application/json"
+)
+
+// kubectlBackend is an implementation of Kubectl for the resource of kubectl
+type kubectlBackend interface {
+	Kubectl
+	resourceKubectl() *kubectl
+	// withKubectl returns the same backend for another resource
+	withKubectl(k *kubectl) kubectlBackend
+}
+
+func (k *kubectl) resourceKubectl() *kubectl {
+	return k
+}
+
+func (k *kubectl) withKubectl(another *kubectl) kubectlBackend {
+	return another
+}
+
+// clientKubectl lists objects with client-go instead of running kubectl.
+// Other operations like previews, reloads on fzf and actions still run kubectl.
+type clientKubectl struct {
+	*kubectl
+	dynamicClient    dynamic.Interface
+	restClient       rest.Interface
+	restMapper       meta.RESTMapper
+	categoryExpander restmapper.CategoryExpander
+	defaultNamespace string
+}
+
+// NewBackend returns the implementation of Kubectl for the backend
+func NewBackend(backend string, k *kubectl) (kubectlBackend, error) {
+	switch backend {
+	case BackendKubectl:
+		return k, nil
+	case BackendClientGo:
+		client, err := NewClientKubectl(k)
+		if err != nil {
+			return nil, err
+		}
+		return client, nil
+	}
+	return nil, fmt.Errorf("backend must be one of [%s, %s]", BackendKubectl, BackendClientGo)
+}
+
+// NewClientKubectl returns kubectl using client-go with the kubeconfig loaded in the same way as kubectl.
+func NewClientKubectl(k *kubectl) (*clientKubectl, error) {
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{
+			CurrentContext: k.kubeContext,
+		},
+	)
+	restConfig, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get the namespace from kubeconfig: %w", err)
+	}
+	return newClientKubectl(k, restConfig, namespace)
+}
+
+func newClientKubectl(k *kubectl, restConfig *rest.Config, defaultNamespace string) (*clientKubectl, error) {
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a dynamic client: %w", err)
+	}
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a discovery client: %w", err)
+	}
+	cachedDiscoveryClient := memory.NewMemCacheClient(discoveryClient)
+	restMapper := restmapper.NewShortcutExpander(restmapper.NewDeferredDiscoveryRESTMapper(cachedDiscoveryClient), cachedDiscoveryClient, nil)
+	if defaultNamespace == "" {
+		defaultNamespace = metav1.NamespaceDefault
+	}
+	return &clientKubectl{
+		kubectl:          k,
+		dynamicClient:    dynamicClient,
+		restClient:       discoveryClient.RESTClient(),
+		restMapper:       restMapper,
+		categoryExpander: restmapper.NewDiscoveryCategoryExpander(cachedDiscoveryClient),
+		defaultNamespace: defaultNamespace,
+	}, nil
+}
+
+func (c clientKubectl) withKubectl(k *kubectl) kubectlBackend {
+	c.kubectl = k
+	return &c
+}
+
+// run supports only "kubectl get" with some options and output formats.
+// Other commands are run by kubectl.
+func (c clientKubectl) run(ctx context.Context, operation string, names []string, options map[string]string) ([]byte, error) {
+	if operation != "get" {
+		return c.kubectl.run(ctx, operation, names, options)
+	}
+	listOptions := clientListOptions{
+		namespace: c.namespace,
+	}
+	if listOptions.namespace == "" {
+		listOptions.namespace = c.defaultNamespace
+	}
+	outputFormat := ""
+	for name, value := range options {
+		switch name {
+		case "-n":
+			listOptions.namespace = value
+		case "--all-namespaces":
+			listOptions.allNamespaces = value == "true"
+		case "--no-headers":
+			listOptions.noHeaders = value == "true"
+		case "--show-labels":
+			listOptions.showLabels = value == "true"
+		case "--selector":
+			listOptions.labelSelector = value
+		case "--field-selector":
+			listOptions.fieldSelector = value
+		case "-o":
+			outputFormat = value
+		default:
+			return c.kubectl.run(ctx, operation, names, options)
+		}
+	}
+	if listOptions.allNamespaces {
+		listOptions.namespace = metav1.NamespaceAll
+	}
+
+	switch outputFormat {
+	case "":
+		if len(names) > 0 {
+			return c.kubectl.run(ctx, operation, names, options)
+		}
+		return c.getTable(ctx, listOptions)
+	case outputFormatName, outputFormatJSON, outputFormatYaml:
+		return c.getObjects(ctx, listOptions, names, outputFormat)
+	}
+	return c.kubectl.run(ctx, operation, names, options)
+}
+
+// clientListOptions are options of kubectl get supported by client-go
+type clientListOptions struct {
+	namespace     string
+	allNamespaces bool
+	noHeaders     bool
+	showLabels    bool
+	labelSelector string
+	fieldSelector string
+}
+
+type clientResource struct {
+	gvr        schema.GroupVersionResource
+	gvk        schema.GroupVersionKind
+	namespaced bool
+}
+
+// kindName returns the prefix of the name printed by kubectl for multiple resources like deployment.apps/name
+func (r clientResource) kindName(name string) string {
+	kind := strings.ToLower(r.gvk.Kind)
+	if r.gvk.Group != "" {
+		kind = kind + "." + r.gvk.Group
+	}
+	return kind + "/" + name
+}
+
+func (r clientResource) path(namespace string) string {
+	p := "/api"
+	if r.gvr.Group != "" {
+		p = path.Join("/apis", r.gvr.Group)
+	}
+	p = path.Join(p, r.gvr.Version)
+	if r.namespaced && namespace != "" {
+		p = path.Join(p, "namespaces", namespace)
+	}
+	return path.Join(p, r.gvr.Resource)
+}
+
+func (c clientKubectl) resolveResources(resourceArg string) ([]clientResource, error) {
+	var groupResources []schema.GroupResource
+	for _, arg := range strings.Split(resourceArg, ",") {
+		if expanded, ok := c.categoryExpander.Expand(arg); ok {
+			groupResources = append(groupResources, expanded...)
+			continue
+		}
+		groupResources = append(groupResources, schema.ParseGroupResource(arg))
+	}
+
+	resources := make([]clientResource, 0, len(groupResources))
+	for _, groupResource := range groupResources {
+		resource, err := c.resolveResource(groupResource)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, resource)
+	}
+	return resources, nil
+}
+
+func (c clientKubectl) resolveResource(groupResource schema.GroupResource) (clientResource, error) {
+	gvr, err := c.restMapper.ResourceFor(groupResource.WithVersion(""))
+	if err != nil {
+		return clientResource{}, fmt.Errorf("the server doesn't have a resource type %q: %w", groupResource.String(), err)
+	}
+	gvk, err := c.restMapper.KindFor(gvr)
+	if err != nil {
+		return clientResource{}, fmt.Errorf("failed to get the kind of %s: %w", gvr.String(), err)
+	}
+	mapping, err := c.restMapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return clientResource{}, fmt.Errorf("failed to get the mapping of %s: %w", gvk.String(), err)
+	}
+	return clientResource{
+		gvr:        gvr,
+		gvk:        gvk,
+		namespaced: mapping.Scope.Name() == meta.RESTScopeNameNamespace,
+	}, nil
+}
+
+// getTable returns the same output as kubectl get
+func (c clientKubectl) getTable(ctx context.Context, listOptions clientListOptions) ([]byte, error) {
+	resources, err := c.resolveResources(c.resource)
+	if err != nil {
+		return nil, err
+	}
+	hasMultipleResources := c.hasMultipleResources()
+
+	var out bytes.Buffer
+	writer := tabwriter.NewWriter(&out, 6, 4, 3, ' ', 0)
+	for _, resource := range resources {
+		request := c.restClient.Get().
+			AbsPath(resource.path(listOptions.namespace)).
+			SetHeader("Accept", tableAcceptHeader)
+		if listOptions.labelSelector != "" {
+			request = request.Param("labelSelector", listOptions.labelSelector)
+		}
+		if listOptions.fieldSelector != "" {
+			request = request.Param("fieldSelector", listOptions.fieldSelector)
+		}
+		raw, err := request.DoRaw(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", resource.gvr.Resource, err)
+		}
+		var table metav1.Table
+		if err := json.Unmarshal(raw, &table); err != nil {
+			return nil, fmt.Errorf("failed to parse the table of %s: %w", resource.gvr.Resource, err)
+		}
+
+		var columnIndexes []int
+		var headers []string
+		if listOptions.allNamespaces && resource.namespaced {
+			headers = append(headers, "NAMESPACE")
+		}
+		for i, column := range table.ColumnDefinitions {
+			if column.Priority != 0 {
+				continue
+			}
+			columnIndexes = append(columnIndexes, i)
+			headers = append(headers, strings.ToUpper(column.Name))
+		}
+		if listOptions.showLabels {
+			headers = append(headers, "LABELS")
+		}
+		if !listOptions.noHeaders && len(table.Rows) > 0 {
+			fmt.Fprintln(writer, strings.Join(headers, "\t"))
+		}
+		for _, row := range table.Rows {
+			var metadata metav1.PartialObjectMetadata
+			if len(row.Object.Raw) > 0 {
+				if err := json.Unmarshal(row.Object.Raw, &metadata); err != nil {
+					return nil, fmt.Errorf("failed to parse the metadata of %s: %w", resource.gvr.Resource, err)
+				}
+			}
+			var cells []string
+			if listOptions.allNamespaces && resource.namespaced {
+				cells = append(cells, metadata.Namespace)
+			}
+			for _, i := range columnIndexes {
+				if i >= len(row.Cells) {
+					cells = append(cells, "")
+					continue
+				}
+				cell := fmt.Sprint(row.Cells[i])
+				if hasMultipleResources && strings.EqualFold(table.ColumnDefinitions[i].Name, "name") {
+					cell = resource.kindName(cell)
+				}
+				cells = append(cells, cell)
+			}
+			if listOptions.showLabels {
+				cells = append(cells, formatLabels(metadata.Labels))
+			}
+			fmt.Fprintln(writer, strings.Join(cells, "\t"))
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return nil, fmt.Errorf("failed to write the table: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// getObjects returns the same output as kubectl get -o with the format
+func (c clientKubectl) getObjects(ctx context.Context, listOptions clientListOptions, names []string, outputFormat string) ([]byte, error) {
+	namespace := listOptions.namespace
+	var objects []unstructured.Unstructured
+	if len(names) == 0 {
+		resources, err := c.resolveResources(c.resource)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resources {
+			list, err := c.resourceInterface(resource, namespace).List(ctx, metav1.ListOptions{
+				LabelSelector: listOptions.labelSelector,
+				FieldSelector: listOptions.fieldSelector,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to list %s: %w", resource.gvr.Resource, err)
+			}
+			objects = append(objects, list.Items...)
+		}
+	}
+	var resource clientResource
+	if len(names) > 0 && !c.hasMultipleResources() {
+		resources, err := c.resolveResources(c.resource)
+		if err != nil {
+			return nil, err
+		}
+		resource = resources[0]
+	}
+	for _, name := range names {
+		objectResource := resource
+		if c.hasMultipleResources() {
+			i := strings.Index(name, "/")
+			if i < 0 {
+				return nil, fmt.Errorf("the name must be like kind/name for multiple resources: %s", name)
+			}
+			var err error
+			objectResource, err = c.resolveResource(schema.ParseGroupResource(name[:i]))
+			if err != nil {
+				return nil, err
+			}
+			name = name[i+1:]
+		}
+		object, err := c.resourceInterface(objectResource, namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get %s %s: %w", objectResource.gvr.Resource, name, err)
+		}
+		objects = append(objects, *object)
+	}
+
+	if outputFormat == outputFormatName {
+		var out bytes.Buffer
+		for _, object := range objects {
+			gvk := object.GroupVersionKind()
+			fmt.Fprintln(&out, clientResource{gvk: gvk}.kindName(object.GetName()))
+		}
+		return out.Bytes(), nil
+	}
+
+	var content interface{}
+	if len(objects) == 1 && len(names) == 1 {
+		content = objects[0].Object
+	} else {
+		items := make([]interface{}, len(objects))
+		for i, object := range objects {
+			items[i] = object.Object
+		}
+		content = map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "List",
+			"items":      items,
+			"metadata": map[string]interface{}{
+				"resourceVersion": "",
+			},
+		}
+	}
+	out, err := json.MarshalIndent(content, "", "    ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal objects: %w", err)
+	}
+	if outputFormat == outputFormatYaml {
+		out, err = yaml.JSONToYAML(out)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert objects to yaml: %w", err)
+		}
+		return out, nil
+	}
+	return append(out, '\n'), nil
+}
+
+// formatLabels returns labels in the same format as kubectl get --show-labels
+func formatLabels(labels map[string]string) string {
+	if len(labels) == 0 {
+		return "<none>"
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + labels[k]
+	}
+	return strings.Join(pairs, ",")
+}
+
+func (c clientKubectl) resourceInterface(resource clientResource, namespace string) dynamic.ResourceInterface {
+	if !resource.namespaced {
+		return c.dynamicClient.Resource(resource.gvr)
+	}
+	return c.dynamicClient.Resource(resource.gvr).Namespace(namespace)
+}
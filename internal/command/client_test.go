@@ -0,0 +1,414 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+// newFakeAPIServer returns an API server with pods, services and deployments
+func newFakeAPIServer(t *testing.T) *httptest.Server {
+	responses := map[string]string{
+		"/api": `{"kind": "APIVersions", "versions": ["v1"]}`,
+		"/apis": `{"kind": "APIGroupList", "apiVersion": "v1", "groups": [
+			{"name": "apps", "versions": [{"groupVersion": "apps/v1", "version": "v1"}], "preferredVersion": {"groupVersion": "apps/v1", "version": "v1"}}
+		]}`,
+		"/api/v1": `{"kind": "APIResourceList", "groupVersion": "v1", "resources": [
+			{"name": "pods", "singularName": "pod", "namespaced": true, "kind": "Pod", "verbs": ["get", "list"], "shortNames": ["po"], "categories": ["all"]},
+			{"name": "services", "singularName": "service", "namespaced": true, "kind": "Service", "verbs": ["get", "list"], "shortNames": ["svc"], "categories": ["all"]},
+			{"name": "namespaces", "singularName": "namespace", "namespaced": false, "kind": "Namespace", "verbs": ["get", "list"], "shortNames": ["ns"]}
+		]}`,
+		"/apis/apps/v1": `{"kind": "APIResourceList", "groupVersion": "apps/v1", "resources": [
+			{"name": "deployments", "singularName": "deployment", "namespaced": true, "kind": "Deployment", "verbs": ["get", "list"], "shortNames": ["deploy"], "categories": ["all"]}
+		]}`,
+		"/api/v1/namespaces/default/pods": `{"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"columnDefinitions": [
+				{"name": "Name", "type": "string", "priority": 0},
+				{"name": "Ready", "type": "string", "priority": 0},
+				{"name": "Status", "type": "string", "priority": 0},
+				{"name": "IP", "type": "string", "priority": 1}
+			],
+			"rows": [
+				{"cells": ["pod1", "1/1", "Running", "10.0.0.1"], "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "pod1", "namespace": "default"}}},
+				{"cells": ["pod2", "0/1", "Pending", "10.0.0.2"], "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "pod2", "namespace": "default"}}}
+			]}`,
+		"/api/v1/pods": `{"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"columnDefinitions": [
+				{"name": "Name", "type": "string", "priority": 0},
+				{"name": "Status", "type": "string", "priority": 0}
+			],
+			"rows": [
+				{"cells": ["pod1", "Running"], "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "pod1", "namespace": "default"}}},
+				{"cells": ["pod3", "Running"], "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "pod3", "namespace": "kube-system"}}}
+			]}`,
+		"/api/v1/namespaces/default/services": `{"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"columnDefinitions": [
+				{"name": "Name", "type": "string", "priority": 0},
+				{"name": "Type", "type": "string", "priority": 0}
+			],
+			"rows": [
+				{"cells": ["svc1", "ClusterIP"], "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "svc1", "namespace": "default"}}}
+			]}`,
+		"/apis/apps/v1/namespaces/default/deployments": `{"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"columnDefinitions": [
+				{"name": "Name", "type": "string", "priority": 0},
+				{"name": "Ready", "type": "string", "priority": 0}
+			],
+			"rows": []}`,
+		"/api/v1/namespaces/default/pods?labelSelector=app%3Dpayments": `{"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"columnDefinitions": [
+				{"name": "Name", "type": "string", "priority": 0},
+				{"name": "Status", "type": "string", "priority": 0}
+			],
+			"rows": [
+				{"cells": ["pod1", "Running"], "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "pod1", "namespace": "default", "labels": {"app": "payments", "app.kubernetes.io/version": "v1"}}}}
+			]}`,
+		"/api/v1/namespaces/default/pods?fieldSelector=status.phase%3DPending": `{"kind": "Table", "apiVersion": "meta.k8s.io/v1",
+			"columnDefinitions": [
+				{"name": "Name", "type": "string", "priority": 0},
+				{"name": "Status", "type": "string", "priority": 0}
+			],
+			"rows": [
+				{"cells": ["pod2", "Pending"], "object": {"kind": "PartialObjectMetadata", "apiVersion": "meta.k8s.io/v1", "metadata": {"name": "pod2", "namespace": "default"}}}
+			]}`,
+		"/api/v1/namespaces/default/pods/pod1":                 `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod1", "namespace": "default"}}`,
+		"/api/v1/namespaces/default/services/svc1":             `{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "svc1", "namespace": "default"}}`,
+		"/apis/apps/v1/namespaces/default/deployments/deploy1": `{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "deploy1", "namespace": "default"}}`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Responses for queries like labelSelector are preferred
+		query := r.URL.Query()
+		query.Del("timeout")
+		response, ok := responses[r.URL.Path+"?"+query.Encode()]
+		if !ok {
+			response, ok = responses[r.URL.Path]
+		}
+		if !ok {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			_, err := w.Write([]byte(`{"kind": "Status", "apiVersion": "v1", "status": "Failure", "reason": "NotFound", "code": 404}`))
+			assert.NoError(t, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, err := w.Write([]byte(response))
+		assert.NoError(t, err)
+	}))
+}
+
+func TestClientKubectl_run(t *testing.T) {
+	server := newFakeAPIServer(t)
+	defer server.Close()
+
+	testCases := []struct {
+		name      string
+		kubectl   *kubectl
+		operation string
+		names     []string
+		options   map[string]string
+		want      string
+		wantErr   error
+	}{
+		{
+			name: "list pods",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			operation: "get",
+			want:      "NAME   READY   STATUS\npod1   1/1     Running\npod2   0/1     Pending\n",
+		},
+		{
+			name: "list pods with a short name across all namespaces",
+			kubectl: &kubectl{
+				resource:      "po",
+				allNamespaces: true,
+			},
+			operation: "get",
+			options: map[string]string{
+				"--all-namespaces": "true",
+			},
+			want: "NAMESPACE     NAME   STATUS\ndefault       pod1   Running\nkube-system   pod3   Running\n",
+		},
+		{
+			name: "list all without headers",
+			kubectl: &kubectl{
+				resource:  kubernetesResourceAll,
+				namespace: "default",
+			},
+			operation: "get",
+			options: map[string]string{
+				"--no-headers": "true",
+			},
+			want: "pod/pod1       1/1   Running\npod/pod2       0/1   Pending\nservice/svc1   ClusterIP\n",
+		},
+		{
+			name: "list pods with a label selector and labels",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			operation: "get",
+			options: map[string]string{
+				"--selector":    "app=payments",
+				"--show-labels": "true",
+			},
+			want: "NAME   STATUS    LABELS\npod1   Running   app=payments,app.kubernetes.io/version=v1\n",
+		},
+		{
+			name: "list pods with a field selector",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			operation: "get",
+			options: map[string]string{
+				"--field-selector": "status.phase=Pending",
+				"--show-labels":    "true",
+			},
+			want: "NAME   STATUS    LABELS\npod2   Pending   <none>\n",
+		},
+		{
+			name: "get a pod as json",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			operation: "get",
+			names:     []string{"pod1"},
+			options: map[string]string{
+				"-o": "json",
+			},
+			want: "{\n    \"apiVersion\": \"v1\",\n    \"kind\": \"Pod\",\n    \"metadata\": {\n        \"name\": \"pod1\",\n        \"namespace\": \"default\"\n    }\n}\n",
+		},
+		{
+			name: "get multiple resources as names",
+			kubectl: &kubectl{
+				resource: "deploy,svc",
+			},
+			operation: "get",
+			names:     []string{"deployment.apps/deploy1", "service/svc1"},
+			options: map[string]string{
+				"-o": "name",
+				"-n": "default",
+			},
+			want: "deployment.apps/deploy1\nservice/svc1\n",
+		},
+		{
+			name: "get a pod as yaml",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			operation: "get",
+			names:     []string{"pod1"},
+			options: map[string]string{
+				"-o": "yaml",
+			},
+			want: "apiVersion: v1\nkind: Pod\nmetadata:\n  name: pod1\n  namespace: default\n",
+		},
+		{
+			name: "unknown resource",
+			kubectl: &kubectl{
+				resource: "unknown",
+			},
+			operation: "get",
+			wantErr:   errors.New(`the server doesn't have a resource type "unknown"`),
+		},
+		{
+			name: "not found",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			operation: "get",
+			names:     []string{"pod9"},
+			options: map[string]string{
+				"-o": "json",
+			},
+			wantErr: errors.New("failed to get pods pod9"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sut, err := newClientKubectl(tc.kubectl, &rest.Config{Host: server.URL}, "")
+			require.NoError(t, err)
+
+			got, gotErr := sut.run(context.Background(), tc.operation, tc.names, tc.options)
+			if tc.wantErr != nil {
+				require.Error(t, gotErr)
+				assert.Contains(t, gotErr.Error(), tc.wantErr.Error())
+				return
+			}
+			require.NoError(t, gotErr)
+			assert.Equal(t, tc.want, string(got))
+		})
+	}
+}
+
+func TestClientKubectl_runWithKubectl(t *testing.T) {
+	backupRunKubectl := runKubectl
+	defer func() {
+		runKubectl = backupRunKubectl
+	}()
+	server := newFakeAPIServer(t)
+	defer server.Close()
+
+	var gotArgs []string
+	runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
+		gotArgs = args
+		return []byte("kubectl output"), nil
+	}
+	sut, err := newClientKubectl(&kubectl{resource: kubernetesResourcePods}, &rest.Config{Host: server.URL}, "")
+	require.NoError(t, err)
+
+	got, gotErr := sut.run(context.Background(), "get", []string{"pod1"}, map[string]string{"-o": "jsonpath={.metadata.uid}"})
+	assert.NoError(t, gotErr)
+	assert.Equal(t, "kubectl output", string(got))
+	assert.Equal(t, []string{"get", "pods", "pod1", "-o=jsonpath={.metadata.uid}"}, gotArgs)
+}
+
+func TestGetCli_Run_clientKubectl(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	backupRunKubectl := runKubectl
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+		runKubectl = backupRunKubectl
+	}()
+	server := newFakeAPIServer(t)
+	defer server.Close()
+
+	runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
+		t.Errorf("kubectl must not be run: %v", args)
+		return nil, nil
+	}
+	runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+		got, err := io.ReadAll(ioIn)
+		require.NoError(t, err)
+		assert.Equal(t, "pod/pod1       1/1   Running\npod/pod2       0/1   Pending\nservice/svc1   ClusterIP\n", string(got))
+		// The 1st line is the key of --expect, which is empty for enter
+		return []byte("\npod/pod1       1/1   Running\nservice/svc1   ClusterIP\n"), nil
+	}
+
+	backend, err := newClientKubectl(&kubectl{resource: kubernetesResourcePods + "," + kubernetesResourceService}, &rest.Config{Host: server.URL}, "")
+	require.NoError(t, err)
+	sut, err := NewGetCli(backend, GetOptions{
+		PreviewFormat: kubectlOutputFormatDescribe,
+		OutputFormat:  outputFormatJSON,
+	})
+	require.NoError(t, err)
+
+	var gotIOOut bytes.Buffer
+	gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, io.Discard)
+	assert.NoError(t, gotErr)
+	assert.Equal(t, `[
+    {
+        "kind": "Pod",
+        "apiVersion": "v1",
+        "namespace": "default",
+        "name": "pod1"
+    },
+    {
+        "kind": "Service",
+        "apiVersion": "v1",
+        "namespace": "default",
+        "name": "svc1"
+    }
+]
+`, gotIOOut.String())
+}
+
+func TestNewBackend(t *testing.T) {
+	k := &kubectl{
+		resource: kubernetesResourcePods,
+	}
+	got, gotErr := NewBackend(BackendKubectl, k)
+	assert.Equal(t, k, got)
+	assert.NoError(t, gotErr)
+
+	got, gotErr = NewBackend("unknown", k)
+	assert.Nil(t, got)
+	assert.Equal(t, errors.New("backend must be one of [kubectl, client-go]"), gotErr)
+}
+
+func TestNewClientKubectl(t *testing.T) {
+	dir, err := os.MkdirTemp("", "kubectl-fzf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	kubeconfigPath := filepath.Join(dir, "config")
+	require.NoError(t, os.WriteFile(kubeconfigPath, []byte(`apiVersion: v1
+kind: Config
+current-context: dev
+clusters:
+- name: dev
+  cluster:
+    server: https://127.0.0.1:6443
+- name: prod
+  cluster:
+    server: https://10.0.0.1
+users:
+- name: admin
+  user:
+    token: token
+contexts:
+- name: dev
+  context:
+    cluster: dev
+    user: admin
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+    namespace: web
+`), 0600))
+	backupKubeconfig, hasKubeconfig := os.LookupEnv("KUBECONFIG")
+	defer func() {
+		if hasKubeconfig {
+			require.NoError(t, os.Setenv("KUBECONFIG", backupKubeconfig))
+			return
+		}
+		require.NoError(t, os.Unsetenv("KUBECONFIG"))
+	}()
+	require.NoError(t, os.Setenv("KUBECONFIG", kubeconfigPath))
+
+	testCases := []struct {
+		name          string
+		kubeContext   string
+		wantNamespace string
+		wantErr       bool
+	}{
+		{
+			name:          "current context",
+			wantNamespace: "default",
+		},
+		{
+			name:          "context with a namespace",
+			kubeContext:   "prod",
+			wantNamespace: "web",
+		},
+		{
+			name:        "unknown context",
+			kubeContext: "unknown",
+			wantErr:     true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := NewClientKubectl(&kubectl{
+				resource:    kubernetesResourcePods,
+				kubeContext: tc.kubeContext,
+			})
+			if tc.wantErr {
+				assert.Error(t, gotErr)
+				return
+			}
+			require.NoError(t, gotErr)
+			assert.Equal(t, tc.wantNamespace, got.defaultNamespace)
+		})
+	}
+}
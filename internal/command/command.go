@@ -7,8 +7,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"os"
+	"net/http"
 	"os/exec"
+	"sort"
 	"strings"
 )
 
@@ -19,68 +20,139 @@ const (
 	kubectlOutputFormatYaml     = "yaml"
 
 	envNameFzfOption = "KUBECTL_FZF_FZF_OPTION"
-)
+	// envNameFzfAPIKey is the API key of fzf to authenticate requests to the --listen server
+	envNameFzfAPIKey = "FZF_API_KEY"
 
-var (
-	defaultFzfOption = strings.Join([]string{
-		"--inline-info",
-		"--multi",
-		"--layout reverse",
-		"--preview '$KUBECTL_FZF_FZF_PREVIEW_OPTION'",
-		"--preview-window down:70%",
-		"--bind ctrl-k:kill-line,ctrl-alt-t:toggle-preview,ctrl-alt-n:preview-down,ctrl-alt-p:preview-up,ctrl-alt-v:preview-page-down",
-	}, " ")
-	singleResourceFzfOption = "--header-lines 1"
+	shellSafeCharacters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_=./:,@%+{}"
 )
 
 var (
 	errorInvalidArgumentKubernetesResource = errors.New("1st argument must be the kind of kubernetes resources")
 
-	runCommandWithFzf = func(ctx context.Context, commandLine string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
-		cmd := exec.CommandContext(ctx, "sh", "-c", commandLine)
-		cmd.Stderr = ioErr
-		cmd.Stdin = ioIn
-		return cmd.Output()
+	errorUnterminatedQuote = errors.New("unterminated quote")
+
+	errorInvalidArgumentWatchInterval = errors.New("watch interval must not be negative")
+
+	errorInvalidArgumentSelectContainers = errors.New("containers can be selected only for pods with the output format name or namespace/name")
+
+	errorInvalidArgumentFilter = errors.New("filter cannot be used with the watch mode or the navigation")
+
+	// errorMultipleMatches is returned when multiple objects match the query on the filter with select-1
+	errorMultipleMatches = errors.New("multiple objects match the query, but only one object can be selected")
+
+	// runCommandWithFzf runs fzf with ioIn as the list of candidates
+	runCommandWithFzf = runFzf
+	// postFzfAction sends an action like reload to fzf running with --listen address and the API key
+	postFzfAction = func(ctx context.Context, address string, apiKey string, action string) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://"+address, strings.NewReader(action))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("x-api-key", apiKey)
+		res, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("fzf returns the status %s for the action %s", res.Status, action)
+		}
+		return nil
 	}
+
 	runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
 		return exec.CommandContext(ctx, "kubectl", args...).CombinedOutput()
 	}
+	runKubectlWithIO = func(ctx context.Context, args []string, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+		cmd := exec.CommandContext(ctx, "kubectl", args...)
+		cmd.Stdin = ioIn
+		cmd.Stdout = ioOut
+		cmd.Stderr = ioErr
+		return cmd.Run()
+	}
 )
 
 type Kubectl interface {
 	getCommand(operation string, resource string, names []string, options map[string]string) string
 	run(ctx context.Context, operation string, names []string, options map[string]string) ([]byte, error)
+	runWithIO(ctx context.Context, operation string, resource string, names []string, options map[string]string, commandArgs []string, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error
 }
 
 type kubectl struct {
-	resource  string
-	namespace string
+	resource      string
+	namespace     string
+	allNamespaces bool
+	// kubeContext is the name of the kubeconfig context. The current context is used if it's empty
+	kubeContext string
 }
 
-func NewKubectl(kubernetesResource string, kubernetesNamespace string) (*kubectl, error) {
+// NewKubectl returns kubectl for the resource.
+// If allNamespaces is true, kubernetesNamespace is ignored and objects are listed across all namespaces.
+func NewKubectl(kubernetesResource string, kubernetesNamespace string, allNamespaces bool, kubeContext string) (*kubectl, error) {
 	if kubernetesResource == "" {
 		return nil, errorInvalidArgumentKubernetesResource
 	}
+	if allNamespaces {
+		kubernetesNamespace = ""
+	}
 	return &kubectl{
-		resource:  kubernetesResource,
-		namespace: kubernetesNamespace,
+		resource:      kubernetesResource,
+		namespace:     kubernetesNamespace,
+		allNamespaces: allNamespaces,
+		kubeContext:   kubeContext,
 	}, nil
 }
 
 func (k kubectl) run(ctx context.Context, operation string, names []string, options map[string]string) ([]byte, error) {
-	out, err := runKubectl(ctx, k.getArguments(operation, k.resource, names, options))
+	resource := k.resource
+	if k.hasMultipleResources() && len(names) > 0 {
+		// names are like pod/name for multiple resources
+		resource = ""
+	}
+	args := k.getArguments(operation, resource, names, options)
+	out, err := runKubectl(ctx, args)
 	if err != nil {
-		message := string(out)
-		if len(message) > 0 {
-			return nil, fmt.Errorf(message)
-		}
-		return nil, err
+		return nil, newKubectlError(args, out, err)
 	}
 	return out, nil
 }
 
+// runWithIO runs kubectl connected to the given IO, so that interactive operations like exec or edit work.
+// commandArgs are passed after "--".
+func (k kubectl) runWithIO(ctx context.Context, operation string, resource string, names []string, options map[string]string, commandArgs []string, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	args := k.getArguments(operation, resource, names, options)
+	if len(commandArgs) > 0 {
+		args = append(append(args, "--"), commandArgs...)
+	}
+	if err := runKubectlWithIO(ctx, args, ioIn, ioOut, ioErr); err != nil {
+		return &KubectlError{
+			Args: args,
+			Err:  err,
+		}
+	}
+	return nil
+}
+
+func (k kubectl) hasMultipleResources() bool {
+	return k.resource == kubernetesResourceAll || strings.Contains(k.resource, ",")
+}
+
+// getCommand returns the command line for a shell.
+// fzf placeholders like {1} in arguments are kept as they are unless arguments need quotes.
 func (k kubectl) getCommand(operation string, resource string, names []string, options map[string]string) string {
-	return "kubectl " + strings.Join(k.getArguments(operation, resource, names, options), " ")
+	args := k.getArguments(operation, resource, names, options)
+	for i, arg := range args {
+		args[i] = quoteArgument(arg)
+	}
+	return "kubectl " + strings.Join(args, " ")
+}
+
+// getCommandName returns kubectl with the context for a shell
+func (k kubectl) getCommandName() string {
+	if k.kubeContext == "" {
+		return "kubectl"
+	}
+	return "kubectl " + quoteArgument("--context="+k.kubeContext)
 }
 
 func (k kubectl) getArguments(operation string, resource string, names []string, options map[string]string) []string {
@@ -91,40 +163,86 @@ func (k kubectl) getArguments(operation string, resource string, names []string,
 		args = append(args, resource)
 	}
 	args = append(args, names...)
+	if k.kubeContext != "" {
+		args = append(args, "--context="+k.kubeContext)
+	}
 	if k.namespace != "" {
 		args = append(args, "-n="+k.namespace)
 	}
-	for k, v := range options {
-		args = append(args, k+"="+v)
+	optionNames := make([]string, 0, len(options))
+	for name := range options {
+		optionNames = append(optionNames, name)
+	}
+	sort.Strings(optionNames)
+	for _, name := range optionNames {
+		args = append(args, name+"="+options[name])
 	}
 	return args
 }
 
-func getFzfOption(previewCommand string, hasMultipleResources bool) (string, error) {
-	fzfOption := os.Getenv(envNameFzfOption)
-	if fzfOption == "" {
-		fzfOption = defaultFzfOption
-		if !hasMultipleResources {
-			fzfOption = fzfOption + " " + singleResourceFzfOption
+// splitArguments splits a command line into arguments in the same way as a shell
+// without expanding any variables or commands.
+func splitArguments(commandLine string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArgument := false
+	var quote rune
+	escaped := false
+	for _, r := range commandLine {
+		if escaped {
+			if quote == '"' && !strings.ContainsRune("\"\\$`", r) {
+				current.WriteRune('\\')
+			}
+			current.WriteRune(r)
+			escaped = false
+			continue
 		}
-	}
-	options := map[string][]string{
-		"KUBECTL_FZF_FZF_PREVIEW_OPTION": {
-			previewCommand,
-		},
-	}
-	var invalidEnvVars []string
-	fzfOption = os.Expand(fzfOption, func(envName string) string {
-		for _, opt := range options[envName] {
-			if opt != "" {
-				return opt
+		switch {
+		case quote == '\'':
+			if r == '\'' {
+				quote = 0
+				continue
+			}
+			current.WriteRune(r)
+		case quote == '"':
+			switch r {
+			case '"':
+				quote = 0
+			case '\\':
+				escaped = true
+			default:
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArgument = true
+		case r == '\\':
+			escaped = true
+			inArgument = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArgument {
+				args = append(args, current.String())
+				current.Reset()
+				inArgument = false
 			}
+		default:
+			current.WriteRune(r)
+			inArgument = true
 		}
-		invalidEnvVars = append(invalidEnvVars, envName)
-		return ""
-	})
-	if len(invalidEnvVars) != 0 {
-		return "", fmt.Errorf("%s has invalid environment variables: %s", envNameFzfOption, strings.Join(invalidEnvVars, ","))
 	}
-	return fzfOption, nil
+	if quote != 0 || escaped {
+		return nil, errorUnterminatedQuote
+	}
+	if inArgument {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// quoteArgument quotes an argument with single quotes if it has characters interpreted by a shell
+func quoteArgument(arg string) string {
+	if arg != "" && strings.Trim(arg, shellSafeCharacters) == "" {
+		return arg
+	}
+	return "'" + strings.Replace(arg, "'", `'\''`, -1) + "'"
 }
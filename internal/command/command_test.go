@@ -4,7 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,11 +28,13 @@ func TestMain(m *testing.M) {
 
 func TestNewKubectl(t *testing.T) {
 	testCases := []struct {
-		name      string
-		resource  string
-		namespace string
-		want      *kubectl
-		wantErr   error
+		name          string
+		resource      string
+		namespace     string
+		allNamespaces bool
+		kubeContext   string
+		want          *kubectl
+		wantErr       error
 	}{
 		{
 			name:      "resource with namespace",
@@ -45,6 +52,25 @@ func TestNewKubectl(t *testing.T) {
 				resource: kubernetesResourcePods,
 			},
 		},
+		{
+			name:          "all namespaces ignore the namespace",
+			resource:      kubernetesResourcePods,
+			namespace:     "default",
+			allNamespaces: true,
+			want: &kubectl{
+				resource:      kubernetesResourcePods,
+				allNamespaces: true,
+			},
+		},
+		{
+			name:        "context",
+			resource:    kubernetesResourcePods,
+			kubeContext: "staging",
+			want: &kubectl{
+				resource:    kubernetesResourcePods,
+				kubeContext: "staging",
+			},
+		},
 		{
 			name:      "no resource",
 			namespace: "default",
@@ -53,7 +79,7 @@ func TestNewKubectl(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			got, gotErr := NewKubectl(tc.resource, tc.namespace)
+			got, gotErr := NewKubectl(tc.resource, tc.namespace, tc.allNamespaces, tc.kubeContext)
 			assert.Equal(t, tc.want, got)
 			assert.Equal(t, tc.wantErr, gotErr)
 		})
@@ -114,6 +140,28 @@ func TestKubectl_getCommand(t *testing.T) {
 			resourceNames: nil,
 			want:          "kubectl get pods",
 		},
+		{
+			name: "context with namespace",
+			kubectl: kubectl{
+				namespace:   "default",
+				kubeContext: "kind-kind",
+			},
+			operation:     "describe",
+			resource:      kubernetesResourcePods,
+			resourceNames: []string{"{1}"},
+			want:          "kubectl describe pods {1} --context=kind-kind -n=default",
+		},
+		{
+			name:          "quoted options",
+			kubectl:       kubectl{},
+			operation:     "get",
+			resource:      kubernetesResourcePods,
+			resourceNames: []string{"{1}"},
+			options: map[string]string{
+				"-o": "jsonpath={.metadata.annotations['a']} $(id)",
+			},
+			want: `kubectl get pods {1} '-o=jsonpath={.metadata.annotations['\''a'\'']} $(id)'`,
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -202,6 +250,25 @@ func TestKubectl_getArguments(t *testing.T) {
 				"pods",
 			},
 		},
+		{
+			name: "context",
+			kubectl: kubectl{
+				kubeContext: "staging",
+				namespace:   "default",
+			},
+			operation: "get",
+			resource:  kubernetesResourcePods,
+			options: map[string]string{
+				"-o": "yaml",
+			},
+			want: []string{
+				"get",
+				"pods",
+				"--context=staging",
+				"-n=default",
+				"-o=yaml",
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -226,6 +293,7 @@ func TestKubectl_Run(t *testing.T) {
 		options       map[string]string
 		kubectlOut    []byte
 		kubectlErr    error
+		wantArgs      []string
 		want          []byte
 		wantErr       error
 	}{
@@ -241,6 +309,28 @@ func TestKubectl_Run(t *testing.T) {
 				"-o": "yaml",
 			},
 			kubectlOut: []byte("pods"),
+			wantArgs:   []string{"get", "pods", "pod1", "-n=default", "-o=yaml"},
+			want:       []byte("pods"),
+		},
+		{
+			name: "names of multiple resources",
+			kubectl: kubectl{
+				resource: kubernetesResourcePods + "," + kubernetesResourceService,
+			},
+			operation:     "get",
+			resourceNames: []string{"pod/pod1", "service/svc1"},
+			kubectlOut:    []byte("pods"),
+			wantArgs:      []string{"get", "pod/pod1", "service/svc1"},
+			want:          []byte("pods"),
+		},
+		{
+			name: "list multiple resources",
+			kubectl: kubectl{
+				resource: kubernetesResourcePods + "," + kubernetesResourceService,
+			},
+			operation:  "get",
+			kubectlOut: []byte("pods"),
+			wantArgs:   []string{"get", "pods,svc"},
 			want:       []byte("pods"),
 		},
 		{
@@ -253,7 +343,11 @@ func TestKubectl_Run(t *testing.T) {
 			kubectlOut:    []byte("server doesn't have a resource type"),
 			kubectlErr:    errors.New("exit status: 1"),
 			want:          nil,
-			wantErr:       errors.New("server doesn't have a resource type"),
+			wantErr: &KubectlError{
+				Args:   []string{"get", "pods", "pod2"},
+				Stderr: "server doesn't have a resource type",
+				Err:    errors.New("exit status: 1"),
+			},
 		},
 		{
 			name: "error without stdout",
@@ -265,12 +359,18 @@ func TestKubectl_Run(t *testing.T) {
 			kubectlOut:    nil,
 			kubectlErr:    errors.New("k executable file not found"),
 			want:          nil,
-			wantErr:       errors.New("k executable file not found"),
+			wantErr: &KubectlError{
+				Args: []string{"get", "pods", "pod2"},
+				Err:  errors.New("k executable file not found"),
+			},
 		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
 			runKubectl = func(ctx context.Context, args []string) (bytes []byte, err error) {
+				if tc.wantArgs != nil {
+					assert.Equal(t, tc.wantArgs, args)
+				}
 				return tc.kubectlOut, tc.kubectlErr
 			}
 
@@ -281,65 +381,204 @@ func TestKubectl_Run(t *testing.T) {
 	}
 }
 
-func TestGetFzfOption(t *testing.T) {
+func TestRunCommandWithFzf(t *testing.T) {
+	dir, err := os.MkdirTemp("", "kubectl-fzf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// The fake fzf prints its arguments and then the candidates given on stdin
+	fakeFzf := "#!/bin/sh\nfor arg in \"$@\"; do echo \"$arg\"; done\ncat\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "fzf"), []byte(fakeFzf), 0755))
+	backupPath := os.Getenv("PATH")
+	defer func() {
+		require.NoError(t, os.Setenv("PATH", backupPath))
+	}()
+	require.NoError(t, os.Setenv("PATH", dir+string(os.PathListSeparator)+backupPath))
+
+	var longList strings.Builder
+	for i := 0; i < 100000; i++ {
+		longList.WriteString(fmt.Sprintf("pod-%d 1/1 Running 2d\n", i))
+	}
 	testCases := []struct {
-		name                 string
-		previewCommand       string
-		hasMultipleResources bool
-		envVars              map[string]string
-		want                 string
-		wantErr              error
+		name  string
+		args  []string
+		input string
 	}{
 		{
-			name:                 "no env vars for multiple resources",
-			previewCommand:       "kubectl describe {{1}}",
-			hasMultipleResources: true,
-			want:                 fmt.Sprintf("--inline-info --multi --layout reverse --preview '%s' --preview-window down:70%% --bind ctrl-k:kill-line,ctrl-alt-t:toggle-preview,ctrl-alt-n:preview-down,ctrl-alt-p:preview-up,ctrl-alt-v:preview-page-down", "kubectl describe {{1}}"),
+			name:  "quotes and command substitutions",
+			args:  []string{"--preview", "kubectl describe pods {1} -n='default'", "--query", "foo bar"},
+			input: "pod-'1' it's $(touch /tmp/kubectl-fzf) `id`\npod2 \"quoted\"\n",
 		},
 		{
-			name:           "no env vars for single resource",
-			previewCommand: "kubectl describe pods {{1}}",
-			want:           fmt.Sprintf("--inline-info --multi --layout reverse --preview '%s' --preview-window down:70%% --bind ctrl-k:kill-line,ctrl-alt-t:toggle-preview,ctrl-alt-n:preview-down,ctrl-alt-p:preview-up,ctrl-alt-v:preview-page-down --header-lines 1", "kubectl describe pods {{1}}"),
+			name:  "very long list",
+			args:  []string{"--multi"},
+			input: longList.String(),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := runCommandWithFzf(context.Background(), tc.args, strings.NewReader(tc.input), io.Discard)
+			require.NoError(t, gotErr)
+			assert.Equal(t, strings.Join(tc.args, "\n")+"\n"+tc.input, string(got))
+		})
+	}
+}
+
+func TestPostFzfAction(t *testing.T) {
+	testCases := []struct {
+		name       string
+		statusCode int
+		wantErr    bool
+	}{
+		{
+			name:       "accepted",
+			statusCode: http.StatusOK,
+		},
+		{
+			name:       "rejected",
+			statusCode: http.StatusBadRequest,
+			wantErr:    true,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var gotAction string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, http.MethodPost, r.Method)
+				assert.Equal(t, "key", r.Header.Get("x-api-key"))
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(t, err)
+				gotAction = string(body)
+				w.WriteHeader(tc.statusCode)
+			}))
+			defer server.Close()
+
+			gotErr := postFzfAction(context.Background(), strings.TrimPrefix(server.URL, "http://"), "key", "reload:kubectl get pods")
+			assert.Equal(t, tc.wantErr, gotErr != nil)
+			assert.Equal(t, "reload:kubectl get pods", gotAction)
+		})
+	}
+}
+
+func TestSplitArguments(t *testing.T) {
+	testCases := []struct {
+		name        string
+		commandLine string
+		want        []string
+		wantErr     error
+	}{
+		{
+			name:        "no quotes",
+			commandLine: "--inline-info  --layout reverse",
+			want:        []string{"--inline-info", "--layout", "reverse"},
+		},
+		{
+			name:        "single quotes",
+			commandLine: "--preview 'kubectl describe pods {1} -n=\"default\"' --bind ctrl-k:kill-line",
+			want:        []string{"--preview", "kubectl describe pods {1} -n=\"default\"", "--bind", "ctrl-k:kill-line"},
+		},
+		{
+			name:        "double quotes with escapes",
+			commandLine: `--query "foo \"bar\" \$HOME \n"`,
+			want:        []string{"--query", `foo "bar" $HOME \n`},
+		},
+		{
+			name:        "escaped space",
+			commandLine: `--query foo\ bar`,
+			want:        []string{"--query", "foo bar"},
+		},
+		{
+			name:        "empty quoted argument",
+			commandLine: `--query ''`,
+			want:        []string{"--query", ""},
+		},
+		{
+			name:        "empty",
+			commandLine: "",
+			want:        nil,
 		},
 		{
-			name:           "all correct env vars",
-			previewCommand: "kubectl describe pods {{1}}",
-			envVars: map[string]string{
-				envNameFzfOption: "--preview '$KUBECTL_FZF_FZF_PREVIEW_OPTION' --bind ctrl-k:kill-line,ctrl-alt-t:toggle-preview,ctrl-alt-n:preview-down,ctrl-alt-p:preview-up,ctrl-alt-v:preview-page-down",
+			name:        "unterminated quote",
+			commandLine: "--preview 'kubectl describe",
+			wantErr:     errorUnterminatedQuote,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := splitArguments(tc.commandLine)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+func TestKubectl_runWithIO(t *testing.T) {
+	backupRunKubectlWithIO := runKubectlWithIO
+	defer func() {
+		runKubectlWithIO = backupRunKubectlWithIO
+	}()
+
+	defaultErr := errors.New("exit status 1")
+	testCases := []struct {
+		name        string
+		kubectl     kubectl
+		operation   string
+		resource    string
+		names       []string
+		options     map[string]string
+		commandArgs []string
+		kubectlErr  error
+		wantArgs    []string
+		wantErr     error
+	}{
+		{
+			name: "exec with command",
+			kubectl: kubectl{
+				resource:  kubernetesResourcePods,
+				namespace: "default",
 			},
-			want: fmt.Sprintf("--preview '%s' --bind %s", "kubectl describe pods {{1}}", "ctrl-k:kill-line,ctrl-alt-t:toggle-preview,ctrl-alt-n:preview-down,ctrl-alt-p:preview-up,ctrl-alt-v:preview-page-down"),
+			operation: "exec",
+			resource:  kubernetesResourcePods,
+			names:     []string{"pod1"},
+			options: map[string]string{
+				"--tty":   "true",
+				"--stdin": "true",
+			},
+			commandArgs: []string{"sh", "-c", "ls"},
+			wantArgs:    []string{"exec", "pods", "pod1", "-n=default", "--stdin=true", "--tty=true", "--", "sh", "-c", "ls"},
 		},
 		{
-			name:           "no preview command in environment variable",
-			previewCommand: "unused preview command",
-			envVars: map[string]string{
-				envNameFzfOption: "--inline-info",
+			name: "describe multiple resources",
+			kubectl: kubectl{
+				resource: kubernetesResourceAll,
 			},
-			want: "--inline-info",
+			operation: "describe",
+			names:     []string{"pod/pod1", "service/svc1"},
+			wantArgs:  []string{"describe", "pod/pod1", "service/svc1"},
 		},
 		{
-			name:           "invalid env vars in KUBECTL_FZF_FZF_OPTION",
-			previewCommand: "unused preview command",
-			envVars: map[string]string{
-				envNameFzfOption: "--inline-info $UNKNOWN_ENV_NAME",
+			name: "error",
+			kubectl: kubectl{
+				resource: kubernetesResourcePods,
+			},
+			operation:  "delete",
+			resource:   kubernetesResourcePods,
+			names:      []string{"pod1"},
+			kubectlErr: defaultErr,
+			wantArgs:   []string{"delete", "pods", "pod1"},
+			wantErr: &KubectlError{
+				Args: []string{"delete", "pods", "pod1"},
+				Err:  defaultErr,
 			},
-			want:    "",
-			wantErr: fmt.Errorf("%s has invalid environment variables: UNKNOWN_ENV_NAME", envNameFzfOption),
 		},
 	}
-
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			defer func() {
-				for k := range tc.envVars {
-					require.NoError(t, os.Unsetenv(k))
-				}
-			}()
-			for k, v := range tc.envVars {
-				require.NoError(t, os.Setenv(k, v))
+			runKubectlWithIO = func(ctx context.Context, args []string, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+				assert.Equal(t, tc.wantArgs, args)
+				return tc.kubectlErr
 			}
-			got, gotErr := getFzfOption(tc.previewCommand, tc.hasMultipleResources)
-			assert.Equal(t, tc.want, got)
+			gotErr := tc.kubectl.runWithIO(context.Background(), tc.operation, tc.resource, tc.names, tc.options, tc.commandArgs, os.Stdin, io.Discard, io.Discard)
 			assert.Equal(t, tc.wantErr, gotErr)
 		})
 	}
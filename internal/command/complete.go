@@ -0,0 +1,234 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+var (
+	errorNotKubectlCommandLine = errors.New("the command line must be kubectl")
+
+	// kubectlFlagsWithValue are flags of kubectl which take the value as the next argument
+	kubectlFlagsWithValue = map[string]bool{
+		"-n": true, "--namespace": true,
+		"--context": true, "--cluster": true, "--user": true, "--kubeconfig": true,
+		"-s": true, "--server": true, "--token": true, "--as": true, "--request-timeout": true,
+		"-l": true, "--selector": true, "--field-selector": true,
+		"-o": true, "--output": true, "--template": true, "--sort-by": true,
+		"-L": true, "--label-columns": true,
+		"-c": true, "--container": true,
+		"--since": true, "--since-time": true, "--tail": true, "--max-log-requests": true,
+		"-f": true, "--filename": true,
+		"--replicas": true, "--timeout": true, "--grace-period": true,
+	}
+	// kubectlBooleanFlagsByVerb are flags of kubectlFlagsWithValue which take no value for the subcommand,
+	// like -f which is --follow for logs instead of --filename
+	kubectlBooleanFlagsByVerb = map[string]map[string]bool{
+		"logs": {"-f": true},
+	}
+	// kubectlPodVerbs are subcommands of kubectl for pods
+	kubectlPodVerbs = map[string]bool{
+		"logs":         true,
+		"exec":         true,
+		"attach":       true,
+		"port-forward": true,
+	}
+	// kubectlResourceVerbs are subcommands of kubectl which take the resource as the 1st argument
+	kubectlResourceVerbs = map[string]bool{
+		"get":      true,
+		"describe": true,
+		"delete":   true,
+		"edit":     true,
+		"label":    true,
+		"annotate": true,
+		"patch":    true,
+		"scale":    true,
+		"top":      true,
+	}
+	// kubectlResourceSubcommandVerbs are subcommands of kubectl which take the resource after another subcommand, like rollout restart
+	kubectlResourceSubcommandVerbs = map[string]bool{
+		"rollout": true,
+		"set":     true,
+	}
+	// commandLineSeparators separate commands on a command line
+	commandLineSeparators = map[string]bool{
+		"|":  true,
+		"||": true,
+		"&&": true,
+		";":  true,
+	}
+)
+
+// CompleteTarget is the objects to select for the kubectl command line being typed
+type CompleteTarget struct {
+	// Resource is empty if the kind of resources is selected at first
+	Resource      string
+	Namespace     string
+	AllNamespaces bool
+	Context       string
+	// Output is the output format of the selected objects for the command line
+	Output string
+	// Word is the name of an object being typed before the cursor, which is replaced by the selected objects
+	Word string
+	// Query is the initial query of fzf from Word, without the kind like pods/
+	Query string
+}
+
+// ParseCommandLine returns the objects to select for the kubectl command line before the cursor,
+// like pods for "kubectl logs " or services in the namespace for "kubectl -n foo describe svc ".
+// If the line ends with a partial name of an object like "kubectl logs ng", it's returned as the word to replace.
+func ParseCommandLine(line string) (*CompleteTarget, error) {
+	args, err := splitArguments(line)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the command line: %w", err)
+	}
+	// Only the last command is completed, like kubectl for "kubectl get pods | grep foo && kubectl describe pods "
+	for i := len(args) - 1; i >= 0; i-- {
+		if commandLineSeparators[args[i]] {
+			args = args[i+1:]
+			break
+		}
+	}
+	if len(args) == 0 || filepath.Base(args[0]) != "kubectl" {
+		return nil, errorNotKubectlCommandLine
+	}
+
+	target := &CompleteTarget{}
+	var positionals []string
+	// typing is true if the last argument is a positional one without a space after it
+	typing := false
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "-") || arg == "-" {
+			positionals = append(positionals, arg)
+			typing = i == len(args)-1 && !strings.HasSuffix(line, " ")
+			continue
+		}
+
+		name, value, hasValue := arg, "", false
+		if j := strings.Index(arg, "="); j >= 0 {
+			name, value, hasValue = arg[:j], arg[j+1:], true
+		} else if !strings.HasPrefix(arg, "--") && len(arg) > 2 && kubectlFlagTakesValue(arg[:2], positionals) {
+			// A short flag with the value like -nfoo
+			name, value, hasValue = arg[:2], arg[2:], true
+		}
+		if !hasValue && kubectlFlagTakesValue(name, positionals) && i+1 < len(args) {
+			i++
+			value = args[i]
+		}
+		switch name {
+		case "-n", "--namespace":
+			target.Namespace = value
+		case "--context":
+			target.Context = value
+		case "-A", "--all-namespaces":
+			target.AllNamespaces = value == "" || value == "true"
+		}
+	}
+
+	resource := getCommandLineResource(positionals)
+	if typing {
+		word := positionals[len(positionals)-1]
+		if r := getCommandLineResource(positionals[:len(positionals)-1]); r != "" || strings.Contains(resource, "/") {
+			// The last argument is not the verb or the resource, but the name of an object, or pods/name
+			target.Word = word
+			target.Query = word[strings.Index(word, "/")+1:]
+			if r != "" {
+				resource = r
+			}
+		}
+	}
+	target.Output = outputFormatName
+	if strings.Contains(target.Word, "/") {
+		target.Output = outputFormatKindName
+	}
+	if i := strings.Index(resource, "/"); i >= 0 {
+		// Objects are specified like pods/name
+		resource = resource[:i]
+		target.Output = outputFormatKindName
+	}
+	if resource == "" {
+		target.Output = outputFormatKindName
+	}
+	target.Resource = resource
+	return target, nil
+}
+
+// kubectlFlagTakesValue returns true if the flag takes the value as the next argument after the positional arguments
+func kubectlFlagTakesValue(name string, positionals []string) bool {
+	if len(positionals) > 0 && kubectlBooleanFlagsByVerb[positionals[0]][name] {
+		return false
+	}
+	return kubectlFlagsWithValue[name]
+}
+
+// getCommandLineResource returns the resource of objects for positional arguments of kubectl, or an empty string
+func getCommandLineResource(positionals []string) string {
+	if len(positionals) == 0 {
+		return ""
+	}
+	verb := positionals[0]
+	switch {
+	case kubectlPodVerbs[verb]:
+		return "pods"
+	case kubectlResourceVerbs[verb] && len(positionals) > 1:
+		return positionals[1]
+	case kubectlResourceSubcommandVerbs[verb] && len(positionals) > 2:
+		return positionals[2]
+	}
+	return ""
+}
+
+type completeCli struct {
+	line string
+	// word is the name of an object being typed, which is replaced by the selected objects
+	word   string
+	getCli *getCli
+}
+
+// NewCompleteCli returns the cli to output the selected objects to insert into the command line.
+// word is CompleteTarget.Word of the line.
+func NewCompleteCli(line string, word string, getCli *getCli) *completeCli {
+	return &completeCli{
+		line:   line,
+		word:   word,
+		getCli: getCli,
+	}
+}
+
+// Run outputs the selected objects quoted for shells, which replace the last word of the command line before the cursor.
+// The last word is output before the objects unless it's the word being typed, so it's kept by shells.
+// Keys of fzf --expect also select objects instead of running actions.
+func (c completeCli) Run(ctx context.Context, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	objects, _, err := c.getCli.selectObjectsWithKey(ctx, ioErr)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	out, err := c.getCli.output.formatObjects(ctx, c.getCli.kubectl, objects)
+	if err != nil {
+		return err
+	}
+
+	var text bytes.Buffer
+	if lastWord := c.line[strings.LastIndex(c.line, " ")+1:]; c.word == "" && lastWord != "" {
+		text.WriteString(lastWord + " ")
+	}
+	for _, name := range strings.Fields(string(out)) {
+		text.WriteString(quoteArgument(name) + " ")
+	}
+	if _, err := ioOut.Write(text.Bytes()); err != nil {
+		return fmt.Errorf("failed to output the result: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,228 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseCommandLine(t *testing.T) {
+	testCases := []struct {
+		name    string
+		line    string
+		want    *CompleteTarget
+		wantErr error
+	}{
+		{
+			name: "logs",
+			line: "kubectl logs ",
+			want: &CompleteTarget{Resource: "pods", Output: outputFormatName},
+		},
+		{
+			name: "describe services",
+			line: "kubectl describe svc ",
+			want: &CompleteTarget{Resource: "svc", Output: outputFormatName},
+		},
+		{
+			name: "namespace before the verb",
+			line: "kubectl -n foo get deploy ",
+			want: &CompleteTarget{Resource: "deploy", Namespace: "foo", Output: outputFormatName},
+		},
+		{
+			name: "namespace with =",
+			line: "kubectl get --namespace=foo cm ",
+			want: &CompleteTarget{Resource: "cm", Namespace: "foo", Output: outputFormatName},
+		},
+		{
+			name: "namespace joined with the short flag",
+			line: "kubectl exec -it -nfoo ",
+			want: &CompleteTarget{Resource: "pods", Namespace: "foo", Output: outputFormatName},
+		},
+		{
+			name: "context and all namespaces",
+			line: "kubectl --context prod get pods -A ",
+			want: &CompleteTarget{Resource: "pods", AllNamespaces: true, Context: "prod", Output: outputFormatName},
+		},
+		{
+			name: "resource after the subcommand",
+			line: "kubectl rollout restart deployment ",
+			want: &CompleteTarget{Resource: "deployment", Output: outputFormatName},
+		},
+		{
+			name: "object with the kind",
+			line: "kubectl get pod/x ",
+			want: &CompleteTarget{Resource: "pod", Output: outputFormatKindName},
+		},
+		{
+			name: "value flags before the resource",
+			line: "kubectl scale --replicas 3 --timeout 1m deploy ",
+			want: &CompleteTarget{Resource: "deploy", Output: outputFormatName},
+		},
+		{
+			name: "value flags of logs",
+			line: "kubectl logs --max-log-requests 10 --tail 5 ",
+			want: &CompleteTarget{Resource: "pods", Output: outputFormatName},
+		},
+		{
+			name: "value flags of delete",
+			line: "kubectl delete -f x.yaml --grace-period 0 pods ",
+			want: &CompleteTarget{Resource: "pods", Output: outputFormatName},
+		},
+		{
+			name: "follow logs",
+			line: "kubectl logs -f ",
+			want: &CompleteTarget{Resource: "pods", Output: outputFormatName},
+		},
+		{
+			name: "partial name after follow of logs",
+			line: "kubectl logs -f ng",
+			want: &CompleteTarget{Resource: "pods", Output: outputFormatName, Word: "ng", Query: "ng"},
+		},
+		{
+			name: "partial name of an object",
+			line: "kubectl logs -n foo ng",
+			want: &CompleteTarget{Resource: "pods", Namespace: "foo", Output: outputFormatName, Word: "ng", Query: "ng"},
+		},
+		{
+			name: "partial name of an object with the kind",
+			line: "kubectl describe deploy/ng",
+			want: &CompleteTarget{Resource: "deploy", Output: outputFormatKindName, Word: "deploy/ng", Query: "ng"},
+		},
+		{
+			name: "partial resource is not the name of an object",
+			line: "kubectl get po",
+			want: &CompleteTarget{Resource: "po", Output: outputFormatName},
+		},
+		{
+			name: "partial flag is not the name of an object",
+			line: "kubectl get pods --all",
+			want: &CompleteTarget{Resource: "pods", Output: outputFormatName},
+		},
+		{
+			name: "no resource",
+			line: "kubectl ",
+			want: &CompleteTarget{Output: outputFormatKindName},
+		},
+		{
+			name: "last command of the pipeline",
+			line: "ls | /usr/local/bin/kubectl get pods",
+			want: &CompleteTarget{Resource: "pods", Output: outputFormatName},
+		},
+		{
+			name:    "not kubectl",
+			line:    "kubectl get pods | grep ",
+			wantErr: errorNotKubectlCommandLine,
+		},
+		{
+			name:    "empty line",
+			line:    "",
+			wantErr: errorNotKubectlCommandLine,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := ParseCommandLine(tc.line)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+
+	_, gotErr := ParseCommandLine(`kubectl get pods -l 'app=`)
+	assert.Error(t, gotErr)
+}
+
+func TestCompleteCli_Run(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	testCases := []struct {
+		name      string
+		line      string
+		word      string
+		format    string
+		fzfOutput string
+		fzfErr    error
+		// objectJSON is the output of kubectl get -o json for the kind of objects
+		objectJSON string
+		want       string
+		wantErr    error
+	}{
+		{
+			name:      "line ending with a space",
+			line:      "kubectl logs ",
+			format:    outputFormatName,
+			fzfOutput: "pod1 2/2 Running 2d\npod2 2/2 Running 2d\n",
+			want:      "pod1 pod2 ",
+		},
+		{
+			name:      "line not ending with a space",
+			line:      "kubectl logs",
+			format:    outputFormatName,
+			fzfOutput: "pod1 2/2 Running 2d\n",
+			want:      "logs pod1 ",
+		},
+		{
+			name:      "partial name of an object is replaced",
+			line:      "kubectl logs po",
+			word:      "po",
+			format:    outputFormatName,
+			fzfOutput: "pod1 2/2 Running 2d\n",
+			want:      "pod1 ",
+		},
+		{
+			name:       "kind and name",
+			line:       "kubectl get ",
+			format:     outputFormatKindName,
+			fzfOutput:  "pod1 2/2 Running 2d\n",
+			objectJSON: `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod1"}}`,
+			want:       "pod/pod1 ",
+		},
+		{
+			name:    "canceled",
+			line:    "kubectl logs ",
+			format:  outputFormatName,
+			fzfErr:  newExitError(t, 130),
+			wantErr: ErrCanceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return([]byte("Name Ready Status Age\npod1 2/2 Running 2d\npod2 2/2 Running 2d"), nil).
+				Times(1)
+			if tc.objectJSON != "" {
+				mockKubectl.EXPECT().
+					run(gomock.Any(), "get", []string{"pod1"}, map[string]string{"-o": outputFormatJSON}).
+					Return([]byte(tc.objectJSON), nil).
+					Times(1)
+			}
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				return []byte(tc.fzfOutput), tc.fzfErr
+			}
+
+			sut := NewCompleteCli(tc.line, tc.word, &getCli{
+				kubectl: mockKubectl,
+				output: &output{
+					format:    tc.format,
+					delimiter: "\n",
+				},
+			})
+			var got bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &got, io.Discard)
+			assert.True(t, errors.Is(gotErr, tc.wantErr))
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// completionKubeContext returns the context of the --context flag to list candidates of completion
+func completionKubeContext(cmd *cobra.Command) string {
+	kubeContext, err := cmd.Flags().GetString("context")
+	if err != nil {
+		return ""
+	}
+	return kubeContext
+}
+
+// CompleteResources completes the resource argument with resources of kubectl api-resources.
+// Resources joined by "," are completed after the last ",".
+func CompleteResources(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	k := &kubectl{
+		kubeContext: completionKubeContext(cmd),
+	}
+	kubectlArgs := k.getArguments("api-resources", "", nil, map[string]string{
+		"--verbs": "list",
+	})
+	out, err := runKubectl(context.Background(), kubectlArgs)
+	if err != nil {
+		cobra.CompErrorln(newKubectlError(kubectlArgs, out, err).Error())
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	var prefix string
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	var candidates []string
+	for _, resource := range parseAPIResources(string(out)) {
+		candidates = append(candidates, fmt.Sprintf("%s%s\t%s", prefix, resource.qualifiedName(), resource.kind))
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteNamespaces completes the flag with namespaces of the context
+func CompleteNamespaces(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	k := &kubectl{
+		kubeContext: completionKubeContext(cmd),
+	}
+	kubectlArgs := k.getArguments("get", "namespaces", nil, map[string]string{
+		"-o": "name",
+	})
+	out, err := runKubectl(context.Background(), kubectlArgs)
+	if err != nil {
+		cobra.CompErrorln(newKubectlError(kubectlArgs, out, err).Error())
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var candidates []string
+	for _, name := range strings.Fields(string(out)) {
+		candidates = append(candidates, strings.TrimPrefix(name, "namespace/"))
+	}
+	return candidates, cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompleteContexts completes the flag with contexts of kubeconfig
+func CompleteContexts(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	k := &kubectl{}
+	kubectlArgs := k.getArguments("config", "get-contexts", nil, map[string]string{
+		"-o": "name",
+	})
+	out, err := runKubectl(context.Background(), kubectlArgs)
+	if err != nil {
+		cobra.CompErrorln(newKubectlError(kubectlArgs, out, err).Error())
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return strings.Fields(string(out)), cobra.ShellCompDirectiveNoFileComp
+}
+
+// CompletePreviewFormats completes the flag with formats of the preview.
+// A format with a value is completed with "=", like jsonpath=, without a space after it.
+func CompletePreviewFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	names := make([]string, 0, len(previewFormats))
+	for name := range previewFormats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	directive := cobra.ShellCompDirectiveNoFileComp
+	var candidates []string
+	for _, name := range names {
+		candidate := name
+		if previewFormats[name].hasValue {
+			candidate = name + "="
+		}
+		if !strings.HasPrefix(candidate, toComplete) {
+			continue
+		}
+		if previewFormats[name].hasValue {
+			directive |= cobra.ShellCompDirectiveNoSpace
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates, directive
+}
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCompletionTestCommand returns the command with completions registered in the same way as kubectl-fzf
+func newCompletionTestCommand(t *testing.T) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:               "kubectl-fzf [resource]",
+		Args:              cobra.ArbitraryArgs,
+		ValidArgsFunction: CompleteResources,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return nil
+		},
+	}
+	flags := cmd.PersistentFlags()
+	flags.StringP("namespace", "n", "", "")
+	flags.String("context", "", "")
+	flags.StringP("preview-format", "p", "", "")
+	require.NoError(t, cmd.RegisterFlagCompletionFunc("namespace", CompleteNamespaces))
+	require.NoError(t, cmd.RegisterFlagCompletionFunc("context", CompleteContexts))
+	require.NoError(t, cmd.RegisterFlagCompletionFunc("preview-format", CompletePreviewFormats))
+	return cmd
+}
+
+func TestCompletion(t *testing.T) {
+	backupRunKubectl := runKubectl
+	defer func() {
+		runKubectl = backupRunKubectl
+	}()
+
+	testCases := []struct {
+		name        string
+		args        []string
+		wantKubectl []string
+		kubectlOut  string
+		kubectlErr  error
+		want        string
+	}{
+		{
+			name:        "resources",
+			args:        []string{""},
+			wantKubectl: []string{"api-resources", "--verbs=list"},
+			kubectlOut:  apiResourcesOutput,
+			want: `configmaps	ConfigMap
+namespaces	Namespace
+pods	Pod
+deployments.apps	Deployment
+certificates.cert-manager.io	Certificate
+widgets.example.com	Widget
+:4
+`,
+		},
+		{
+			name:        "resources after a comma in the context",
+			args:        []string{"--context", "prod", "pods,"},
+			wantKubectl: []string{"api-resources", "--context=prod", "--verbs=list"},
+			kubectlOut: `NAME       SHORTNAMES   APIVERSION   NAMESPACED   KIND
+services   svc          v1           true         Service
+`,
+			want: `pods,services	Service
+:4
+`,
+		},
+		{
+			name: "no more resources",
+			args: []string{"pods", ""},
+			want: ":4\n",
+		},
+		{
+			name:        "kubectl error",
+			args:        []string{""},
+			wantKubectl: []string{"api-resources", "--verbs=list"},
+			kubectlOut:  "error: unable to connect\n",
+			kubectlErr:  errors.New("exit status 1"),
+			want:        ":1\n",
+		},
+		{
+			name:        "namespaces",
+			args:        []string{"--context=prod", "-n", ""},
+			wantKubectl: []string{"get", "namespaces", "--context=prod", "-o=name"},
+			kubectlOut:  "namespace/default\nnamespace/kube-system\n",
+			want:        "default\nkube-system\n:4\n",
+		},
+		{
+			name:        "contexts",
+			args:        []string{"--context", ""},
+			wantKubectl: []string{"config", "get-contexts", "-o=name"},
+			kubectlOut:  "dev\nprod\n",
+			want:        "dev\nprod\n:4\n",
+		},
+		{
+			name: "preview formats",
+			args: []string{"--preview-format", ""},
+			want: "custom-columns=\ndescribe\nevents\njson\njsonpath=\nlogs\nsecret\ntemplate=\ntop\nyaml\n:6\n",
+		},
+		{
+			name: "preview formats without a value",
+			args: []string{"-p", "d"},
+			want: "describe\n:4\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
+				assert.Equal(t, tc.wantKubectl, args)
+				return []byte(tc.kubectlOut), tc.kubectlErr
+			}
+
+			cmd := newCompletionTestCommand(t)
+			var got bytes.Buffer
+			cmd.SetOut(&got)
+			cmd.SetErr(&bytes.Buffer{})
+			cmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, tc.args...))
+			require.NoError(t, cmd.Execute())
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
@@ -0,0 +1,339 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// EnvNameConfig is the environment variable of the path of the config file
+	EnvNameConfig = "KUBECTL_FZF_CONFIG"
+
+	// customColumnName is the column of the name required at first for custom columns
+	customColumnName      = "NAME:.metadata.name"
+	customColumnNamespace = "NAMESPACE:.metadata.namespace"
+)
+
+// Config is the config file of kubectl-fzf in YAML
+type Config struct {
+	// Aliases are resources for aliases, like deploy: deployments.apps
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Output is the default output format
+	Output string `json:"output,omitempty"`
+	// PreviewFormat is the default preview format
+	PreviewFormat string `json:"previewFormat,omitempty"`
+	// Bindings are key bindings of fzf added to the default ones, like ctrl-y: execute-silent(echo {1} | pbcopy)
+	Bindings map[string]string `json:"bindings,omitempty"`
+	// ActionKeys are keys of bindings running kubectl for the object on the cursor, like logs: ctrl-l.
+	// A binding is disabled by an empty key
+	ActionKeys map[string]string `json:"actionKeys,omitempty"`
+	// ExpectKeys are keys to finish fzf and actions run on selected objects, like ctrl-f: logs --follow.
+	// A key is disabled by an empty action
+	ExpectKeys map[string]string `json:"expectKeys,omitempty"`
+	// Resources are configs for each resource after aliases are resolved, like pods or deployments.apps
+	Resources map[string]ResourceConfig `json:"resources,omitempty"`
+	// Shell is the config of key bindings of shells written by kubectl fzf init
+	Shell ShellConfig `json:"shell,omitempty"`
+	// DisableSecretReveal is true not to reveal values of secrets on the secret preview, like on shared terminals
+	DisableSecretReveal bool `json:"disableSecretReveal,omitempty"`
+}
+
+// ShellConfig is the config of key bindings of shells
+type ShellConfig struct {
+	// Prefix is the prefix key like ctrl-x,ctrl-k. No prefix key is used if it's empty
+	Prefix *string `json:"prefix,omitempty"`
+	// Bindings are keys after the prefix and resources selected by them, which replace the default ones.
+	// An empty resource selects the kind of resources at first
+	Bindings map[string]string `json:"bindings,omitempty"`
+	// CompleteKey is the key after the prefix to select objects for the command line being typed.
+	// It's disabled if it's empty
+	CompleteKey *string `json:"completeKey,omitempty"`
+}
+
+// ResourceConfig is the config for objects of a resource
+type ResourceConfig struct {
+	// PreviewFormat is the default preview format for the resource
+	PreviewFormat string `json:"previewFormat,omitempty"`
+	// Preview is the template of the preview command, which is the same as the preview format template=...
+	Preview string `json:"preview,omitempty"`
+	// Output is the default output format for the resource
+	Output string `json:"output,omitempty"`
+	// Columns are custom columns to list objects, like STATUS:.status.phase.
+	// The 1st column must be NAME:.metadata.name
+	Columns []string `json:"columns,omitempty"`
+}
+
+// DefaultConfigPath returns the path of the config file under $XDG_CONFIG_HOME, or ~/.config
+func DefaultConfigPath() (string, error) {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the home directory: %w", err)
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "kubectl-fzf", "config.yaml"), nil
+}
+
+// LoadConfig returns the config in the path.
+// If mustExist is false, an empty config is returned when the file doesn't exist.
+func LoadConfig(path string, mustExist bool) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !mustExist {
+			return &Config{}, nil
+		}
+		return nil, fmt.Errorf("failed to read the config file: %w", err)
+	}
+	var config Config
+	if err := yaml.UnmarshalStrict(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the config file %s: %w", path, err)
+	}
+	if err := config.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config file %s: %w", path, err)
+	}
+	return &config, nil
+}
+
+func (c Config) validate() error {
+	for alias, resource := range c.Aliases {
+		if alias == "" || resource == "" {
+			return fmt.Errorf("aliases must not be empty: %q: %q", alias, resource)
+		}
+	}
+	if c.PreviewFormat != "" {
+		if _, _, _, err := parsePreviewFormat(c.PreviewFormat); err != nil {
+			return fmt.Errorf("previewFormat: %w", err)
+		}
+	}
+	if c.Output != "" && !isValidOutputFormat(c.Output) {
+		return fmt.Errorf("output: %w", errorInvalidArgumentOutputFormat)
+	}
+	for key, action := range c.Bindings {
+		if key == "" || action == "" {
+			return fmt.Errorf("bindings must not be empty: %q: %q", key, action)
+		}
+	}
+	for name := range c.ActionKeys {
+		if _, ok := defaultBindingKeys[name]; !ok {
+			return fmt.Errorf("actionKeys: %s must be one of [%s]", name, strings.Join(bindingNames, ", "))
+		}
+	}
+	for key, action := range c.ExpectKeys {
+		if key == "" || key == "enter" {
+			return fmt.Errorf("expectKeys: %q cannot be used", key)
+		}
+		if action == "" {
+			continue
+		}
+		if _, _, err := parseExpectAction(action); err != nil {
+			return fmt.Errorf("expectKeys.%s: %w", key, err)
+		}
+	}
+	if c.Shell.Prefix != nil || len(c.Shell.Bindings) > 0 || c.Shell.CompleteKey != nil {
+		if _, err := NewInitCli(ShellBash, c.ShellPrefix(), c.ShellBindings(), c.ShellCompleteKey()); err != nil {
+			return fmt.Errorf("shell: %w", err)
+		}
+	}
+	for resource, config := range c.Resources {
+		if strings.Contains(resource, ",") {
+			return fmt.Errorf("resources.%s: a config cannot be defined for multiple resources", resource)
+		}
+		if err := config.validate(); err != nil {
+			return fmt.Errorf("resources.%s.%w", resource, err)
+		}
+		if c.DisableSecretReveal && isSecretResource(resource) && !hidesSecretValues(c.ResourcePreviewFormat(resource)) {
+			return fmt.Errorf("resources.%s.previewFormat: %w", resource, errorSecretRevealDisabled)
+		}
+	}
+	return nil
+}
+
+func (c ResourceConfig) validate() error {
+	if c.PreviewFormat != "" && c.Preview != "" {
+		return errors.New("preview: previewFormat and preview cannot be used at once")
+	}
+	if c.PreviewFormat != "" {
+		if _, _, _, err := parsePreviewFormat(c.PreviewFormat); err != nil {
+			return fmt.Errorf("previewFormat: %w", err)
+		}
+	}
+	if _, err := template.New("preview").Parse(c.Preview); err != nil {
+		return fmt.Errorf("preview: %w", err)
+	}
+	if c.Output != "" && !isValidOutputFormat(c.Output) {
+		return fmt.Errorf("output: %w", errorInvalidArgumentOutputFormat)
+	}
+	for i, column := range c.Columns {
+		if i == 0 && column != customColumnName {
+			return fmt.Errorf("columns: the 1st column must be %s", customColumnName)
+		}
+		if header, path := splitCustomColumn(column); header == "" || path == "" {
+			return fmt.Errorf("columns: %q must be HEADER:.json.path", column)
+		}
+	}
+	return nil
+}
+
+func splitCustomColumn(column string) (string, string) {
+	i := strings.Index(column, ":")
+	if i < 0 {
+		return "", ""
+	}
+	return column[:i], column[i+1:]
+}
+
+// ResolveAlias returns the resource for aliases, which can be multiple resources joined by ","
+func (c Config) ResolveAlias(resource string) string {
+	resources := strings.Split(resource, ",")
+	for i, r := range resources {
+		if aliased, ok := c.Aliases[r]; ok {
+			resources[i] = aliased
+		}
+	}
+	return strings.Join(resources, ",")
+}
+
+// ResourcePreviewFormat returns the default preview format for the resource, or an empty string if it's not configured.
+// Secrets are previewed with decoded values unless the preview format is configured,
+// or if the default preview format shows their values when they cannot be revealed.
+func (c Config) ResourcePreviewFormat(resource string) string {
+	config := c.Resources[resource]
+	if config.Preview != "" {
+		return previewFormatTemplate + "=" + config.Preview
+	}
+	if config.PreviewFormat != "" {
+		return config.PreviewFormat
+	}
+	// The default preview format is not used if it shows values of secrets which cannot be revealed
+	if c.DisableSecretReveal && hasSecretResource(resource) && !hidesSecretValues(c.PreviewFormat) {
+		if isSecretResource(resource) {
+			return previewFormatSecret
+		}
+		// The secret preview cannot be used with other resources
+		return kubectlOutputFormatDescribe
+	}
+	if c.PreviewFormat == "" && isSecretResource(resource) {
+		return previewFormatSecret
+	}
+	return c.PreviewFormat
+}
+
+// ResourceOutputFormat returns the default output format for the resource, or an empty string if it's not configured
+func (c Config) ResourceOutputFormat(resource string) string {
+	if output := c.Resources[resource].Output; output != "" {
+		return output
+	}
+	return c.Output
+}
+
+// ResourceGetOptions returns options of kubectl get for the columns of the resource.
+// The namespace column is added at first across all namespaces, because rows are parsed by the 1st and 2nd columns.
+func (c Config) ResourceGetOptions(resource string, allNamespaces bool) map[string]string {
+	columns := c.Resources[resource].Columns
+	if len(columns) == 0 {
+		return nil
+	}
+	if allNamespaces {
+		columns = append([]string{customColumnNamespace}, columns...)
+	}
+	return map[string]string{
+		"-o": "custom-columns=" + strings.Join(columns, ","),
+	}
+}
+
+// ShellPrefix returns the prefix key of key bindings of shells
+func (c Config) ShellPrefix() string {
+	if c.Shell.Prefix == nil {
+		return DefaultShellPrefix
+	}
+	return *c.Shell.Prefix
+}
+
+// ShellBindings returns keys of key bindings of shells and resources selected by them
+func (c Config) ShellBindings() map[string]string {
+	if len(c.Shell.Bindings) == 0 {
+		return defaultShellBindings
+	}
+	return c.Shell.Bindings
+}
+
+// ShellCompleteKey returns the key of key bindings of shells to select objects for the command line being typed
+func (c Config) ShellCompleteKey() string {
+	if c.Shell.CompleteKey == nil {
+		return DefaultShellCompleteKey
+	}
+	return *c.Shell.CompleteKey
+}
+
+// fzfBindings returns key bindings of fzf in the config file sorted by keys, which are added to the default ones
+func (c Config) fzfBindings() []fzfBinding {
+	keys := make([]string, 0, len(c.Bindings))
+	for key := range c.Bindings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	bindings := make([]fzfBinding, 0, len(keys))
+	for _, key := range keys {
+		bindings = append(bindings, fzfBinding{
+			key:    key,
+			action: c.Bindings[key],
+		})
+	}
+	return bindings
+}
+
+// resolvedConfig is the config of a cli resolved from the config file and flags, with keys merged into the default ones
+type resolvedConfig struct {
+	// bindingKeys are the keys of bindings running kubectl. A binding is disabled if the key is empty
+	bindingKeys map[string]string
+	// expectKeys are keys of fzf --expect and actions run by them. A key is disabled by an empty action
+	expectKeys map[string]string
+	// fzfBindings are key bindings of fzf in the config file, added to the default ones
+	fzfBindings []fzfBinding
+	// secretRevealDisabled is true not to reveal values of secrets
+	secretRevealDisabled bool
+	logsPreview          LogsPreviewOptions
+}
+
+// newResolvedConfig returns the config resolved from the config file and options of the logs preview.
+// The default ones are used for nil.
+func newResolvedConfig(config *Config, logsPreview *LogsPreviewOptions) (*resolvedConfig, error) {
+	if config == nil {
+		config = &Config{}
+	}
+	resolved := &resolvedConfig{
+		bindingKeys:          make(map[string]string, len(defaultBindingKeys)),
+		expectKeys:           make(map[string]string, len(defaultExpectKeys)),
+		fzfBindings:          config.fzfBindings(),
+		secretRevealDisabled: config.DisableSecretReveal,
+		logsPreview:          defaultLogsPreviewOptions,
+	}
+	for name, key := range defaultBindingKeys {
+		resolved.bindingKeys[name] = key
+	}
+	for name, key := range config.ActionKeys {
+		resolved.bindingKeys[name] = key
+	}
+	for key, action := range defaultExpectKeys {
+		resolved.expectKeys[key] = action
+	}
+	for key, action := range config.ExpectKeys {
+		resolved.expectKeys[key] = action
+	}
+	if logsPreview != nil {
+		if err := logsPreview.validate(); err != nil {
+			return nil, err
+		}
+		resolved.logsPreview = *logsPreview
+	}
+	return resolved, nil
+}
@@ -0,0 +1,314 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultConfigPath(t *testing.T) {
+	backupXDGConfigHome, hasXDGConfigHome := os.LookupEnv("XDG_CONFIG_HOME")
+	backupHome := os.Getenv("HOME")
+	defer func() {
+		if hasXDGConfigHome {
+			require.NoError(t, os.Setenv("XDG_CONFIG_HOME", backupXDGConfigHome))
+		} else {
+			require.NoError(t, os.Unsetenv("XDG_CONFIG_HOME"))
+		}
+		require.NoError(t, os.Setenv("HOME", backupHome))
+	}()
+	require.NoError(t, os.Setenv("HOME", "/home/user"))
+
+	require.NoError(t, os.Setenv("XDG_CONFIG_HOME", "/xdg"))
+	got, err := DefaultConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/xdg/kubectl-fzf/config.yaml", got)
+
+	require.NoError(t, os.Unsetenv("XDG_CONFIG_HOME"))
+	got, err = DefaultConfigPath()
+	require.NoError(t, err)
+	assert.Equal(t, "/home/user/.config/kubectl-fzf/config.yaml", got)
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir, err := os.MkdirTemp("", "kubectl-fzf")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		name    string
+		content string
+		want    *Config
+		wantErr string
+	}{
+		{
+			name: "all fields",
+			content: `aliases:
+  deploy: deployments.apps
+output: namespace/name
+previewFormat: yaml
+bindings:
+  ctrl-y: execute-silent(echo {1} | pbcopy)
+resources:
+  pods:
+    previewFormat: logs
+    columns:
+    - NAME:.metadata.name
+    - STATUS:.status.phase
+  deployments.apps:
+    preview: kubectl rollout history deployments {{ .Name }} -n {{ .Namespace }}
+    output: json
+`,
+			want: &Config{
+				Aliases:       map[string]string{"deploy": "deployments.apps"},
+				Output:        "namespace/name",
+				PreviewFormat: "yaml",
+				Bindings:      map[string]string{"ctrl-y": "execute-silent(echo {1} | pbcopy)"},
+				Resources: map[string]ResourceConfig{
+					"pods": {
+						PreviewFormat: "logs",
+						Columns:       []string{"NAME:.metadata.name", "STATUS:.status.phase"},
+					},
+					"deployments.apps": {
+						Preview: "kubectl rollout history deployments {{ .Name }} -n {{ .Namespace }}",
+						Output:  "json",
+					},
+				},
+			},
+		},
+		{
+			name:    "an unknown field",
+			content: "alias:\n  deploy: deployments.apps\n",
+			wantErr: `failed to parse the config file %s: error unmarshaling JSON: while decoding JSON: json: unknown field "alias"`,
+		},
+		{
+			name:    "an invalid preview format",
+			content: "previewFormat: jsonpath\n",
+			wantErr: "invalid config file %s: previewFormat: " + errorInvalidArgumentFZFPreviewCommand.Error(),
+		},
+		{
+			name:    "an invalid output format",
+			content: "resources:\n  pods:\n    output: wide\n",
+			wantErr: "invalid config file %s: resources.pods.output: " + errorInvalidArgumentOutputFormat.Error(),
+		},
+		{
+			name:    "both preview and previewFormat",
+			content: "resources:\n  pods:\n    preview: echo {{ .Name }}\n    previewFormat: yaml\n",
+			wantErr: "invalid config file %s: resources.pods.preview: previewFormat and preview cannot be used at once",
+		},
+		{
+			name:    "an invalid preview template",
+			content: "resources:\n  pods:\n    preview: echo {{ .Name\n",
+			wantErr: `invalid config file %s: resources.pods.preview: template: preview:1: unclosed action`,
+		},
+		{
+			name:    "columns without the name at first",
+			content: "resources:\n  pods:\n    columns:\n    - STATUS:.status.phase\n",
+			wantErr: "invalid config file %s: resources.pods.columns: the 1st column must be NAME:.metadata.name",
+		},
+		{
+			name:    "an invalid column",
+			content: "resources:\n  pods:\n    columns:\n    - NAME:.metadata.name\n    - STATUS\n",
+			wantErr: `invalid config file %s: resources.pods.columns: "STATUS" must be HEADER:.json.path`,
+		},
+		{
+			name:    "multiple resources",
+			content: "resources:\n  pods,services:\n    previewFormat: yaml\n",
+			wantErr: "invalid config file %s: resources.pods,services: a config cannot be defined for multiple resources",
+		},
+		{
+			name:    "a preview format showing values of secrets which cannot be revealed",
+			content: "disableSecretReveal: true\nresources:\n  secrets:\n    previewFormat: yaml\n",
+			wantErr: "invalid config file %s: resources.secrets.previewFormat: values of secrets cannot be revealed by disableSecretReveal of the config file",
+		},
+		{
+			name:    "an unknown action key",
+			content: "actionKeys:\n  describe: ctrl-i\n",
+			wantErr: "invalid config file %s: actionKeys: describe must be one of [reload, logs, exec, delete, toggle-preview, toggle-previous-logs, reveal-secret]",
+		},
+		{
+			name:    "an invalid expect action",
+			content: "expectKeys:\n  ctrl-a: apply\n",
+			wantErr: "invalid config file %s: expectKeys.ctrl-a: expect action must be one of [print, delete, describe, edit, exec, logs]",
+		},
+		{
+			name:    "enter in expect keys",
+			content: "expectKeys:\n  enter: describe\n",
+			wantErr: `invalid config file %s: expectKeys: "enter" cannot be used`,
+		},
+		{
+			name:    "an invalid shell key",
+			content: "shell:\n  bindings:\n    C-p: pods\n",
+			wantErr: `invalid config file %s: shell: key must be like ctrl-p or alt-p: "C-p"`,
+		},
+		{
+			name:    "an empty alias",
+			content: "aliases:\n  deploy: ''\n",
+			wantErr: `invalid config file %s: aliases must not be empty: "deploy": ""`,
+		},
+	}
+	for i, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			path := filepath.Join(dir, fmt.Sprintf("config%d.yaml", i))
+			require.NoError(t, os.WriteFile(path, []byte(tc.content), 0644))
+			got, gotErr := LoadConfig(path, true)
+			assert.Equal(t, tc.want, got)
+			if tc.wantErr == "" {
+				assert.NoError(t, gotErr)
+			} else {
+				assert.EqualError(t, gotErr, fmt.Sprintf(tc.wantErr, path))
+			}
+		})
+	}
+
+	t.Run("no file on the default path", func(t *testing.T) {
+		got, gotErr := LoadConfig(filepath.Join(dir, "unknown.yaml"), false)
+		assert.Equal(t, &Config{}, got)
+		assert.NoError(t, gotErr)
+	})
+	t.Run("no file on the specified path", func(t *testing.T) {
+		got, gotErr := LoadConfig(filepath.Join(dir, "unknown.yaml"), true)
+		assert.Nil(t, got)
+		assert.True(t, errors.Is(gotErr, os.ErrNotExist))
+	})
+}
+
+func TestConfig_ResolveAlias(t *testing.T) {
+	sut := Config{
+		Aliases: map[string]string{
+			"deploy": "deployments.apps",
+			"po":     "pods",
+		},
+	}
+	assert.Equal(t, "deployments.apps", sut.ResolveAlias("deploy"))
+	assert.Equal(t, "pods,services,deployments.apps", sut.ResolveAlias("po,services,deploy"))
+	assert.Equal(t, "services", sut.ResolveAlias("services"))
+}
+
+func TestConfig_Resource(t *testing.T) {
+	sut := Config{
+		Output:        "namespace/name",
+		PreviewFormat: "yaml",
+		Resources: map[string]ResourceConfig{
+			"pods": {
+				PreviewFormat: "logs",
+				Output:        "json",
+				Columns:       []string{"NAME:.metadata.name", "STATUS:.status.phase"},
+			},
+			"deployments.apps": {
+				Preview: "kubectl rollout history deployments {{ .Name }}",
+			},
+			"secret": {
+				PreviewFormat: "describe",
+			},
+		},
+	}
+
+	assert.Equal(t, "logs", sut.ResourcePreviewFormat("pods"))
+	assert.Equal(t, "template=kubectl rollout history deployments {{ .Name }}", sut.ResourcePreviewFormat("deployments.apps"))
+	assert.Equal(t, "yaml", sut.ResourcePreviewFormat("services"))
+	assert.Equal(t, "yaml", sut.ResourcePreviewFormat("secrets"))
+	assert.Equal(t, "describe", sut.ResourcePreviewFormat("secret"))
+	assert.Equal(t, "secret", Config{}.ResourcePreviewFormat("secrets"))
+	assert.Equal(t, "", Config{}.ResourcePreviewFormat("pods,secrets"))
+
+	// The default preview format showing values is not used for secrets which cannot be revealed
+	sut.DisableSecretReveal = true
+	assert.Equal(t, "secret", sut.ResourcePreviewFormat("secrets"))
+	assert.Equal(t, "describe", sut.ResourcePreviewFormat("pods,secrets"))
+	assert.Equal(t, "yaml", sut.ResourcePreviewFormat("services"))
+	sut.PreviewFormat = "events"
+	assert.Equal(t, "events", sut.ResourcePreviewFormat("secrets"))
+
+	assert.Equal(t, "json", sut.ResourceOutputFormat("pods"))
+	assert.Equal(t, "namespace/name", sut.ResourceOutputFormat("services"))
+
+	assert.Equal(t, map[string]string{
+		"-o": "custom-columns=NAME:.metadata.name,STATUS:.status.phase",
+	}, sut.ResourceGetOptions("pods", false))
+	assert.Equal(t, map[string]string{
+		"-o": "custom-columns=NAMESPACE:.metadata.namespace,NAME:.metadata.name,STATUS:.status.phase",
+	}, sut.ResourceGetOptions("pods", true))
+	assert.Nil(t, sut.ResourceGetOptions("services", false))
+}
+
+func TestConfig_Shell(t *testing.T) {
+	sut := Config{}
+	assert.Equal(t, DefaultShellPrefix, sut.ShellPrefix())
+	assert.Equal(t, defaultShellBindings, sut.ShellBindings())
+	assert.Equal(t, DefaultShellCompleteKey, sut.ShellCompleteKey())
+
+	prefix := ""
+	completeKey := ""
+	sut = Config{
+		Shell: ShellConfig{
+			Prefix:      &prefix,
+			Bindings:    map[string]string{"alt-p": "pods"},
+			CompleteKey: &completeKey,
+		},
+	}
+	assert.Equal(t, "", sut.ShellPrefix())
+	assert.Equal(t, map[string]string{"alt-p": "pods"}, sut.ShellBindings())
+	assert.Equal(t, "", sut.ShellCompleteKey())
+}
+
+func TestNewResolvedConfig(t *testing.T) {
+	got, err := newResolvedConfig(&Config{
+		Bindings: map[string]string{
+			"ctrl-y": "execute-silent(echo {1} | pbcopy)",
+			"ctrl-k": "up",
+		},
+		ActionKeys: map[string]string{
+			bindingTogglePreview: "ctrl-t",
+			bindingDelete:        "",
+		},
+		ExpectKeys: map[string]string{
+			"ctrl-x": "",
+			"ctrl-p": "print",
+		},
+		DisableSecretReveal: true,
+	}, &LogsPreviewOptions{Tail: 10, Since: "5m"})
+	require.NoError(t, err)
+	assert.Equal(t, &resolvedConfig{
+		bindingKeys: map[string]string{
+			bindingReload:             "ctrl-r",
+			bindingLogs:               "ctrl-l",
+			bindingExec:               "ctrl-e",
+			bindingDelete:             "",
+			bindingTogglePreview:      "ctrl-t",
+			bindingTogglePreviousLogs: "alt-p",
+			bindingRevealSecret:       "alt-r",
+		},
+		expectKeys: map[string]string{
+			"ctrl-o": "describe",
+			"ctrl-f": "logs --follow",
+			"ctrl-x": "",
+			"ctrl-p": "print",
+		},
+		fzfBindings: []fzfBinding{
+			{key: "ctrl-k", action: "up"},
+			{key: "ctrl-y", action: "execute-silent(echo {1} | pbcopy)"},
+		},
+		secretRevealDisabled: true,
+		logsPreview:          LogsPreviewOptions{Tail: 10, Since: "5m"},
+	}, got)
+
+	// The default keys are not changed by the config
+	got.bindingKeys[bindingReload] = "f5"
+	assert.Equal(t, "ctrl-r", defaultBindingKeys[bindingReload])
+
+	got, err = newResolvedConfig(nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, defaultBindingKeys, got.bindingKeys)
+	assert.Equal(t, defaultExpectKeys, got.expectKeys)
+	assert.Empty(t, got.fzfBindings)
+	assert.Equal(t, defaultLogsPreviewOptions, got.logsPreview)
+
+	_, err = newResolvedConfig(nil, &LogsPreviewOptions{Tail: -2})
+	assert.Equal(t, errorInvalidArgumentLogsTail, err)
+}
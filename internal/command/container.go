@@ -0,0 +1,167 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+const (
+	containerTypeContainer = "container"
+	containerTypeInit      = "init"
+	containerTypeEphemeral = "ephemeral"
+)
+
+// podContainer is a container shown on fzf after a pod is selected
+type podContainer struct {
+	name          string
+	containerType string
+	image         string
+	ready         bool
+	restarts      int
+}
+
+// kubernetesPod is a subset of a pod returned by "kubectl get -o json"
+type kubernetesPod struct {
+	Spec struct {
+		Containers          []kubernetesContainer `json:"containers"`
+		InitContainers      []kubernetesContainer `json:"initContainers"`
+		EphemeralContainers []kubernetesContainer `json:"ephemeralContainers"`
+	} `json:"spec"`
+	Status struct {
+		ContainerStatuses          []kubernetesContainerStatus `json:"containerStatuses"`
+		InitContainerStatuses      []kubernetesContainerStatus `json:"initContainerStatuses"`
+		EphemeralContainerStatuses []kubernetesContainerStatus `json:"ephemeralContainerStatuses"`
+	} `json:"status"`
+}
+
+type kubernetesContainer struct {
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type kubernetesContainerStatus struct {
+	Name         string `json:"name"`
+	Ready        bool   `json:"ready"`
+	RestartCount int    `json:"restartCount"`
+}
+
+// isPodResource returns true if the resource of kubectl is only pods
+func isPodResource(k *kubectl) bool {
+	if k.hasMultipleResources() {
+		return false
+	}
+	for _, name := range kubernetesResourceNamesPod {
+		if strings.ToLower(k.resource) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// getPodContainers returns containers, init containers and ephemeral containers of the pod
+func getPodContainers(ctx context.Context, k Kubectl, object resourceObject) ([]podContainer, error) {
+	options := map[string]string{
+		"-o": "json",
+	}
+	if object.namespace != "" {
+		options["-n"] = object.namespace
+	}
+	out, err := k.run(ctx, "get", []string{object.name}, options)
+	if err != nil {
+		return nil, err
+	}
+	var pod kubernetesPod
+	if err := json.Unmarshal(out, &pod); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of kubectl: %w", err)
+	}
+
+	var containers []podContainer
+	appendContainers := func(containerType string, specs []kubernetesContainer, statuses []kubernetesContainerStatus) {
+		statusByName := make(map[string]kubernetesContainerStatus, len(statuses))
+		for _, status := range statuses {
+			statusByName[status.Name] = status
+		}
+		for _, spec := range specs {
+			status := statusByName[spec.Name]
+			containers = append(containers, podContainer{
+				name:          spec.Name,
+				containerType: containerType,
+				image:         spec.Image,
+				ready:         status.Ready,
+				restarts:      status.RestartCount,
+			})
+		}
+	}
+	appendContainers(containerTypeContainer, pod.Spec.Containers, pod.Status.ContainerStatuses)
+	appendContainers(containerTypeInit, pod.Spec.InitContainers, pod.Status.InitContainerStatuses)
+	appendContainers(containerTypeEphemeral, pod.Spec.EphemeralContainers, pod.Status.EphemeralContainerStatuses)
+	return containers, nil
+}
+
+// selectContainer returns the name of the container of the pod selected on fzf.
+// fzf is not run if the pod has only one container, and it returns ErrCanceled if fzf is canceled, or ErrNoMatch if no containers match the query.
+func (c getCli) selectContainer(ctx context.Context, object resourceObject, ioErr io.Writer) (string, error) {
+	containers, err := getPodContainers(ctx, c.kubectl, object)
+	if err != nil {
+		return "", err
+	}
+	if len(containers) == 0 {
+		return "", fmt.Errorf("pod %s has no containers", object)
+	}
+	if len(containers) == 1 {
+		return containers[0].name, nil
+	}
+
+	var candidates bytes.Buffer
+	writer := tabwriter.NewWriter(&candidates, 6, 4, 3, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tTYPE\tIMAGE\tREADY\tRESTARTS")
+	for _, container := range containers {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%d\n", container.name, container.containerType, container.image, strconv.FormatBool(container.ready), container.restarts)
+	}
+	if err := writer.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write containers: %w", err)
+	}
+
+	fzfArgs, err := c.getContainerFzfArgs(object)
+	if err != nil {
+		return "", err
+	}
+	out, err := c.finder.run(ctx, fzfArgs, &candidates, ioErr)
+	if err != nil {
+		if isFinderCanceled(err) {
+			return "", ErrCanceled
+		}
+		if isFinderNoMatch(err) {
+			return "", ErrNoMatch
+		}
+		return "", fmt.Errorf("failed to run the command %s: %w", getFzfCommandLine(fzfArgs), err)
+	}
+	columns := strings.Fields(string(out))
+	if len(columns) == 0 {
+		return "", nil
+	}
+	return columns[0], nil
+}
+
+// getContainerFzfArgs returns fzf arguments to select a container with the preview of its logs
+func (c getCli) getContainerFzfArgs(object resourceObject) ([]string, error) {
+	options := c.config.logsPreview.options(c.config.logsPreview.Previous)
+	options["-c"] = "{1}"
+	if object.namespace != "" {
+		options["-n"] = object.namespace
+	}
+	previewCommand := c.kubectl.getCommand("logs", "", []string{object.name}, options)
+	fzfOptions, err := newFzfOptions(previewCommand, true, c.config.fzfBindings)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fzf option: %w", err)
+	}
+	fzfOptions.multi = false
+	fzfOptions.header = "Select a container of " + object.String()
+	return fzfOptions.args(), nil
+}
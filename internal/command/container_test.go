@@ -0,0 +1,148 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multiContainerPodJSON = `{
+    "apiVersion": "v1",
+    "kind": "Pod",
+    "metadata": {"name": "pod1", "namespace": "default"},
+    "spec": {
+        "containers": [
+            {"name": "app", "image": "example/app:v1"},
+            {"name": "istio-proxy", "image": "istio/proxyv2:1.20"}
+        ],
+        "initContainers": [
+            {"name": "istio-init", "image": "istio/proxyv2:1.20"}
+        ],
+        "ephemeralContainers": [
+            {"name": "debugger", "image": "busybox"}
+        ]
+    },
+    "status": {
+        "containerStatuses": [
+            {"name": "istio-proxy", "ready": true, "restartCount": 0},
+            {"name": "app", "ready": false, "restartCount": 3}
+        ],
+        "initContainerStatuses": [
+            {"name": "istio-init", "ready": true, "restartCount": 0}
+        ]
+    }
+}`
+
+func TestGetPodContainers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockKubectl := NewMockKubectl(mockCtrl)
+	mockKubectl.EXPECT().
+		run(gomock.Any(), "get", []string{"pod1"}, map[string]string{"-o": "json", "-n": "default"}).
+		Return([]byte(multiContainerPodJSON), nil).
+		Times(1)
+
+	got, gotErr := getPodContainers(context.Background(), mockKubectl, resourceObject{namespace: "default", name: "pod1"})
+	assert.NoError(t, gotErr)
+	assert.Equal(t, []podContainer{
+		{name: "app", containerType: containerTypeContainer, image: "example/app:v1", ready: false, restarts: 3},
+		{name: "istio-proxy", containerType: containerTypeContainer, image: "istio/proxyv2:1.20", ready: true, restarts: 0},
+		{name: "istio-init", containerType: containerTypeInit, image: "istio/proxyv2:1.20", ready: true, restarts: 0},
+		{name: "debugger", containerType: containerTypeEphemeral, image: "busybox", ready: false, restarts: 0},
+	}, got)
+}
+
+func TestGetCli_selectContainer(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	wantFzfInput := `NAME          TYPE        IMAGE                READY   RESTARTS
+app           container   example/app:v1       false   3
+istio-proxy   container   istio/proxyv2:1.20   true    0
+istio-init    init        istio/proxyv2:1.20   true    0
+debugger      ephemeral   busybox              false   0
+`
+	testCases := []struct {
+		name       string
+		kubectlOut string
+		kubectlErr error
+		fzfOut     string
+		fzfErr     error
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "select a container",
+			kubectlOut: multiContainerPodJSON,
+			fzfOut:     "istio-proxy   container   istio/proxyv2:1.20   true    0\n",
+			want:       "istio-proxy",
+		},
+		{
+			name:       "only one container",
+			kubectlOut: `{"spec": {"containers": [{"name": "app", "image": "example/app:v1"}]}}`,
+			want:       "app",
+		},
+		{
+			name:       "fzf is canceled",
+			kubectlOut: multiContainerPodJSON,
+			fzfErr:     newExitError(t, 130),
+			wantErr:    ErrCanceled,
+		},
+		{
+			name:       "no containers match the query",
+			kubectlOut: multiContainerPodJSON,
+			fzfErr:     newExitError(t, 1),
+			wantErr:    ErrNoMatch,
+		},
+		{
+			name:       "kubectl error",
+			kubectlErr: errors.New("pods \"pod1\" not found"),
+			wantErr:    errors.New("pods \"pod1\" not found"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), "get", []string{"pod1"}, map[string]string{"-o": "json"}).
+				Return([]byte(tc.kubectlOut), tc.kubectlErr).
+				Times(1)
+			mockKubectl.EXPECT().
+				getCommand("logs", "", []string{"pod1"}, map[string]string{"--tail": "100", "-c": "{1}"}).
+				Return("kubectl logs pod1 --tail=100 -c={1}").
+				AnyTimes()
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				assert.Equal(t, append([]string{
+					"--inline-info",
+					"--no-multi",
+					"--layout", "reverse",
+					"--preview", "kubectl logs pod1 --tail=100 -c={1}",
+					"--preview-window", "down:70%",
+					"--header-lines", "1",
+					"--header", "Select a container of pod1",
+				}, defaultFzfBindArgs...), args)
+				got, err := io.ReadAll(ioIn)
+				require.NoError(t, err)
+				assert.Equal(t, wantFzfInput, string(got))
+				return []byte(tc.fzfOut), tc.fzfErr
+			}
+
+			sut := getCli{
+				kubectl: mockKubectl,
+				config:  &resolvedConfig{logsPreview: defaultLogsPreviewOptions},
+			}
+			got, gotErr := sut.selectContainer(context.Background(), resourceObject{name: "pod1"}, io.Discard)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+var errorNoKubeContexts = errors.New("there are no contexts in kubeconfig")
+
+type contextCli struct {
+	kubectl       *kubectl
+	finder        finder
+	fzfArgs       []string
+	switchContext bool
+}
+
+// kubeconfig is a subset of "kubectl config view -o json"
+type kubeconfig struct {
+	CurrentContext string `json:"current-context"`
+	Contexts       []struct {
+		Name    string `json:"name"`
+		Context struct {
+			Cluster   string `json:"cluster"`
+			User      string `json:"user"`
+			Namespace string `json:"namespace"`
+		} `json:"context"`
+	} `json:"contexts"`
+	Clusters []struct {
+		Name    string `json:"name"`
+		Cluster struct {
+			Server string `json:"server"`
+		} `json:"cluster"`
+	} `json:"clusters"`
+}
+
+// NewContextCli returns the cli to select a context of kubeconfig on fzf.
+// If switchContext is true, the current context is switched to the selected one.
+// finderName is one of FinderAuto, FinderFzf or FinderBuiltin.
+func NewContextCli(fzfQuery string, switchContext bool, finderName string, config *Config) (*contextCli, error) {
+	finder, err := newFinder(finderName)
+	if err != nil {
+		return nil, err
+	}
+	k := &kubectl{}
+	// The preview shows the cluster, the user and the namespace of the context
+	previewCommand := k.getCommand("config", "view", nil, map[string]string{
+		"--minify":  "true",
+		"--context": "{1}",
+	})
+	fzfOptions, err := newFzfOptions(previewCommand, true, config.fzfBindings())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fzf option: %w", err)
+	}
+	fzfOptions.multi = false
+	fzfOptions.query = fzfQuery
+	return &contextCli{
+		kubectl:       k,
+		finder:        finder,
+		fzfArgs:       fzfOptions.args(),
+		switchContext: switchContext,
+	}, nil
+}
+
+func (c contextCli) Run(ctx context.Context, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	name, err := c.selectContext(ctx, ioErr)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return nil
+	}
+	if c.switchContext {
+		return c.kubectl.runWithIO(ctx, "config", "use-context", []string{name}, nil, nil, ioIn, ioOut, ioErr)
+	}
+	if _, err := fmt.Fprintln(ioOut, name); err != nil {
+		return fmt.Errorf("failed to output the result: %w", err)
+	}
+	return nil
+}
+
+// selectContext returns the name of the context selected on fzf.
+// It returns ErrCanceled if fzf is canceled, or ErrNoMatch if no contexts match the query.
+func (c contextCli) selectContext(ctx context.Context, ioErr io.Writer) (string, error) {
+	args := c.kubectl.getArguments("config", "view", nil, map[string]string{
+		"-o": "json",
+	})
+	out, err := runKubectl(ctx, args)
+	if err != nil {
+		return "", newKubectlError(args, out, err)
+	}
+	var config kubeconfig
+	if err := json.Unmarshal(out, &config); err != nil {
+		return "", fmt.Errorf("failed to parse the output of kubectl: %w", err)
+	}
+	if len(config.Contexts) == 0 {
+		return "", errorNoKubeContexts
+	}
+	servers := map[string]string{}
+	for _, cluster := range config.Clusters {
+		servers[cluster.Name] = cluster.Cluster.Server
+	}
+
+	var candidates bytes.Buffer
+	writer := tabwriter.NewWriter(&candidates, 6, 4, 3, ' ', 0)
+	fmt.Fprintln(writer, "NAME\tCLUSTER\tSERVER\tUSER\tNAMESPACE\tCURRENT")
+	for _, kubeContext := range config.Contexts {
+		current := ""
+		if kubeContext.Name == config.CurrentContext {
+			current = "*"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			kubeContext.Name,
+			kubeContext.Context.Cluster,
+			servers[kubeContext.Context.Cluster],
+			kubeContext.Context.User,
+			kubeContext.Context.Namespace,
+			current,
+		)
+	}
+	if err := writer.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write contexts: %w", err)
+	}
+
+	out, err = c.finder.run(ctx, c.fzfArgs, &candidates, ioErr)
+	if err != nil {
+		if isFinderCanceled(err) {
+			return "", ErrCanceled
+		}
+		if isFinderNoMatch(err) {
+			return "", ErrNoMatch
+		}
+		return "", fmt.Errorf("failed to run the command %s: %w", getFzfCommandLine(c.fzfArgs), err)
+	}
+	columns := strings.Fields(string(out))
+	if len(columns) == 0 {
+		return "", nil
+	}
+	return columns[0], nil
+}
@@ -0,0 +1,147 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const kubeconfigJSON = `{
+    "kind": "Config",
+    "apiVersion": "v1",
+    "current-context": "kind-kind",
+    "clusters": [
+        {"name": "kind-kind", "cluster": {"server": "https://127.0.0.1:6443"}},
+        {"name": "gke_project_zone_prod", "cluster": {"server": "https://10.0.0.1"}}
+    ],
+    "users": [],
+    "contexts": [
+        {"name": "kind-kind", "context": {"cluster": "kind-kind", "user": "kind-kind"}},
+        {"name": "prod", "context": {"cluster": "gke_project_zone_prod", "user": "admin", "namespace": "web"}}
+    ]
+}`
+
+func TestNewContextCli(t *testing.T) {
+	got, gotErr := NewContextCli("prod", true, FinderBuiltin, &Config{})
+	assert.NoError(t, gotErr)
+	assert.Equal(t, &contextCli{
+		kubectl: &kubectl{},
+		finder:  finder{builtin: true},
+		fzfArgs: append([]string{
+			"--inline-info",
+			"--no-multi",
+			"--layout", "reverse",
+			"--preview", "kubectl config view --context={1} --minify=true",
+			"--preview-window", "down:70%",
+			"--header-lines", "1",
+			"--query", "prod",
+		}, defaultFzfBindArgs...),
+		switchContext: true,
+	}, got)
+}
+
+func TestContextCli_Run(t *testing.T) {
+	backupRunKubectl := runKubectl
+	backupRunKubectlWithIO := runKubectlWithIO
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runKubectl = backupRunKubectl
+		runKubectlWithIO = backupRunKubectlWithIO
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	// The CURRENT column is padded for the context which is not current
+	wantFzfInput := "NAME        CLUSTER                 SERVER                   USER        NAMESPACE   CURRENT\n" +
+		"kind-kind   kind-kind               https://127.0.0.1:6443   kind-kind               *\n" +
+		"prod        gke_project_zone_prod   https://10.0.0.1         admin       web         \n"
+	testCases := []struct {
+		name              string
+		switchContext     bool
+		kubectlOut        string
+		fzfOut            string
+		fzfErr            error
+		wantKubectlWithIO []string
+		wantIO            string
+		wantErr           error
+	}{
+		{
+			name:       "output the context",
+			kubectlOut: kubeconfigJSON,
+			fzfOut:     "prod   gke_project_zone_prod   https://10.0.0.1   admin   web\n",
+			wantIO:     "prod\n",
+		},
+		{
+			name:              "switch the context",
+			switchContext:     true,
+			kubectlOut:        kubeconfigJSON,
+			fzfOut:            "prod   gke_project_zone_prod   https://10.0.0.1   admin   web\n",
+			wantKubectlWithIO: []string{"config", "use-context", "prod"},
+		},
+		{
+			name:          "fzf is canceled",
+			switchContext: true,
+			kubectlOut:    kubeconfigJSON,
+			fzfErr:        newExitError(t, 130),
+			wantErr:       ErrCanceled,
+		},
+		{
+			name:          "no contexts match the query",
+			switchContext: true,
+			kubectlOut:    kubeconfigJSON,
+			fzfErr:        newExitError(t, 1),
+			wantErr:       ErrNoMatch,
+		},
+		{
+			name:       "no contexts",
+			kubectlOut: `{"kind": "Config", "apiVersion": "v1", "contexts": null}`,
+			wantErr:    errorNoKubeContexts,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
+				assert.Equal(t, []string{"config", "view", "-o=json"}, args)
+				return []byte(tc.kubectlOut), nil
+			}
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				got, err := io.ReadAll(ioIn)
+				require.NoError(t, err)
+				assert.Equal(t, wantFzfInput, string(got))
+				return []byte(tc.fzfOut), tc.fzfErr
+			}
+			var gotKubectlWithIO []string
+			runKubectlWithIO = func(ctx context.Context, args []string, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+				gotKubectlWithIO = args
+				return nil
+			}
+
+			sut, err := NewContextCli("", tc.switchContext, FinderFzf, &Config{})
+			require.NoError(t, err)
+			var gotIOOut bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, io.Discard)
+			assert.Equal(t, tc.wantErr, gotErr)
+			assert.Equal(t, tc.wantIO, gotIOOut.String())
+			assert.Equal(t, tc.wantKubectlWithIO, gotKubectlWithIO)
+		})
+	}
+
+	t.Run("kubectl error", func(t *testing.T) {
+		runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
+			return []byte("error: no configuration\n"), errors.New("exit status 1")
+		}
+		sut, err := NewContextCli("", false, FinderFzf, &Config{})
+		require.NoError(t, err)
+		gotErr := sut.Run(context.Background(), strings.NewReader(""), io.Discard, io.Discard)
+		assert.Equal(t, &KubectlError{
+			Args:   []string{"config", "view", "-o=json"},
+			Stderr: "error: no configuration\n",
+			Err:    errors.New("exit status 1"),
+		}, gotErr)
+	})
+}
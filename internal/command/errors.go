@@ -0,0 +1,87 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrCanceled is returned when the selection on the finder is canceled by Ctrl-c or Esc
+	ErrCanceled = errors.New("the selection is canceled")
+	// ErrNoMatch is returned when no objects match the query, like --filter or --exit-0
+	ErrNoMatch = errors.New("no objects match the query")
+	// ErrNoObjects is returned when kubectl lists no objects to select
+	ErrNoObjects = errors.New("no objects found")
+	// ErrNamespaceNotFound is returned when the namespace to list objects does not exist
+	ErrNamespaceNotFound = errors.New("namespace not found")
+	// ErrUnknownResource is returned when the server doesn't have the resource type
+	ErrUnknownResource = errors.New("unknown resource type")
+	// ErrForbidden is returned when listing objects is forbidden by RBAC
+	ErrForbidden = errors.New("forbidden")
+	// ErrFinderNotFound is returned when fzf is not found in PATH with --finder fzf
+	ErrFinderNotFound = errors.New("fzf is not found in PATH. Install fzf, or use --finder builtin")
+)
+
+// KubectlError is returned when kubectl fails
+type KubectlError struct {
+	// Args are arguments of kubectl
+	Args []string
+	// Stderr is the message of kubectl, which is empty if it's written to the terminal directly
+	Stderr string
+	Err    error
+}
+
+// newKubectlError returns the error of kubectl with the output of it
+func newKubectlError(args []string, out []byte, err error) *KubectlError {
+	return &KubectlError{
+		Args:   args,
+		Stderr: string(out),
+		Err:    err,
+	}
+}
+
+func (e *KubectlError) Error() string {
+	if message := strings.TrimSpace(e.Stderr); message != "" {
+		return message
+	}
+	return fmt.Sprintf("failed to run kubectl %s: %v", strings.Join(e.Args, " "), e.Err)
+}
+
+func (e *KubectlError) Unwrap() error {
+	return e.Err
+}
+
+// ExitError is returned when kubectl of an interactive action like exec exits with the code.
+// The message is already shown on the terminal, and kubectl-fzf exits with the same code.
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// listError is returned when objects cannot be listed for the reason like ErrNoObjects.
+// err is the error of kubectl if any.
+type listError struct {
+	reason  error
+	message string
+	err     error
+}
+
+func (e *listError) Error() string {
+	return e.message
+}
+
+func (e *listError) Unwrap() []error {
+	if e.err == nil {
+		return []error{e.reason}
+	}
+	return []error{e.reason, e.err}
+}
@@ -0,0 +1,41 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKubectlError(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	testCases := []struct {
+		name string
+		err  *KubectlError
+		want string
+	}{
+		{
+			name: "message of kubectl",
+			err: &KubectlError{
+				Args:   []string{"get", "pods"},
+				Stderr: "The connection to the server localhost:8080 was refused\n",
+				Err:    exitErr,
+			},
+			want: "The connection to the server localhost:8080 was refused",
+		},
+		{
+			name: "no message",
+			err: &KubectlError{
+				Args: []string{"exec", "pod1", "--", "sh"},
+				Err:  exitErr,
+			},
+			want: "failed to run kubectl exec pod1 -- sh: exit status 1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.err.Error())
+			assert.True(t, errors.Is(tc.err, exitErr))
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// expectActionPrint prints selected objects in the output format, which is the same as enter
+	expectActionPrint = "print"
+)
+
+var (
+	// defaultExpectKeys are keys of fzf --expect and actions run on selected objects by them
+	defaultExpectKeys = map[string]string{
+		"ctrl-o": "describe",
+		"ctrl-f": "logs --follow",
+		"ctrl-x": "exec",
+	}
+)
+
+// expectAction is run on objects selected by a key of fzf --expect
+type expectAction struct {
+	// action is nil to print objects
+	action *actionCli
+	// selectContainers is true to select a container of each selected pod for the action
+	selectContainers bool
+}
+
+// parseExpectAction parses an action like "logs --follow" into the name and options of kubectl
+func parseExpectAction(value string) (string, map[string]string, error) {
+	args, err := splitArguments(value)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(args) == 0 {
+		return "", nil, errors.New("expect action must not be empty")
+	}
+	name := args[0]
+	if _, ok := kubectlActions[name]; !ok && name != expectActionPrint {
+		return "", nil, fmt.Errorf("expect action must be one of [%s, %s]", expectActionPrint, strings.Join(ActionNames(), ", "))
+	}
+	if name == expectActionPrint && len(args) > 1 {
+		return "", nil, fmt.Errorf("expect action %s cannot have options", expectActionPrint)
+	}
+	var options map[string]string
+	for _, arg := range args[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			return "", nil, fmt.Errorf("expect action %s must have only options like --follow: %s", name, arg)
+		}
+		if options == nil {
+			options = map[string]string{}
+		}
+		if i := strings.Index(arg, "="); i >= 0 {
+			options[arg[:i]] = arg[i+1:]
+			continue
+		}
+		options[arg] = "true"
+	}
+	return name, options, nil
+}
+
+// getExpectActions returns actions for keys of fzf --expect.
+// Keys of the navigation are not used with the navigation, and logs and exec are only for pods.
+func getExpectActions(backend kubectlBackend, navigate bool, config *resolvedConfig) (map[string]expectAction, error) {
+	k := backend.resourceKubectl()
+	var actions map[string]expectAction
+	for key, value := range config.expectKeys {
+		if value == "" {
+			continue
+		}
+		if navigate && (key == navigationKeyOwned || key == navigationKeyOwners) {
+			continue
+		}
+		name, options, err := parseExpectAction(value)
+		if err != nil {
+			return nil, err
+		}
+		if actions == nil {
+			actions = map[string]expectAction{}
+		}
+		if name == expectActionPrint {
+			actions[key] = expectAction{}
+			continue
+		}
+		selectContainers := kubectlActions[name].selectContainer
+		if selectContainers && !isPodResource(k) {
+			continue
+		}
+		action, err := newActionCli(backend, name, options, nil)
+		if err != nil {
+			return nil, err
+		}
+		actions[key] = expectAction{
+			action:           action,
+			selectContainers: selectContainers,
+		}
+	}
+	return actions, nil
+}
+
+// getExpectKeys returns the sorted keys of the actions for fzf --expect
+func getExpectKeys(actions map[string]expectAction) []string {
+	keys := make([]string, 0, len(actions))
+	for key := range actions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// runExpectAction runs the action on the objects, or prints them
+func (c getCli) runExpectAction(ctx context.Context, action expectAction, objects []resourceObject, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	if len(objects) == 0 {
+		return nil
+	}
+	if action.action == nil {
+		return c.printObjects(ctx, objects, ioOut)
+	}
+	for i, object := range objects {
+		if !action.selectContainers {
+			// Containers selected for the other action are not used
+			objects[i].container = ""
+			continue
+		}
+		if object.container != "" {
+			continue
+		}
+		container, err := c.selectContainer(ctx, object, ioErr)
+		if err != nil {
+			return err
+		}
+		if container == "" {
+			return nil
+		}
+		objects[i].container = container
+	}
+	return action.action.run(ctx, objects, ioIn, ioOut, ioErr)
+}
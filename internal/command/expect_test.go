@@ -0,0 +1,243 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseExpectAction(t *testing.T) {
+	testCases := []struct {
+		value       string
+		wantName    string
+		wantOptions map[string]string
+		wantErr     error
+	}{
+		{
+			value:    "describe",
+			wantName: "describe",
+		},
+		{
+			value:    "logs --follow --tail=10",
+			wantName: "logs",
+			wantOptions: map[string]string{
+				"--follow": "true",
+				"--tail":   "10",
+			},
+		},
+		{
+			value:    "print",
+			wantName: "print",
+		},
+		{
+			value:   "print --follow",
+			wantErr: errors.New("expect action print cannot have options"),
+		},
+		{
+			value:   "apply",
+			wantErr: errors.New("expect action must be one of [print, delete, describe, edit, exec, logs]"),
+		},
+		{
+			value:   "exec bash",
+			wantErr: errors.New("expect action exec must have only options like --follow: bash"),
+		},
+		{
+			value:   "",
+			wantErr: errors.New("expect action must not be empty"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			gotName, gotOptions, gotErr := parseExpectAction(tc.value)
+			assert.Equal(t, tc.wantName, gotName)
+			assert.Equal(t, tc.wantOptions, gotOptions)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+func TestGetExpectActions(t *testing.T) {
+	testCases := []struct {
+		name       string
+		kubectl    *kubectl
+		navigate   bool
+		expectKeys map[string]string
+		want       map[string]expectAction
+	}{
+		{
+			name: "default keys for pods",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			expectKeys: defaultExpectKeys,
+			want: map[string]expectAction{
+				"ctrl-o": {
+					action: &actionCli{
+						kubectl:  &kubectl{resource: kubernetesResourcePods},
+						resource: kubernetesResourcePods,
+						action:   kubectlActions["describe"],
+						options:  map[string]string{},
+					},
+				},
+				"ctrl-f": {
+					action: &actionCli{
+						kubectl:  &kubectl{resource: kubernetesResourcePods},
+						resource: kubernetesResourcePods,
+						action:   kubectlActions["logs"],
+						options:  map[string]string{"--follow": "true"},
+					},
+					selectContainers: true,
+				},
+				"ctrl-x": {
+					action: &actionCli{
+						kubectl:     &kubectl{resource: kubernetesResourcePods},
+						resource:    kubernetesResourcePods,
+						action:      kubectlActions["exec"],
+						options:     map[string]string{"--stdin": "true", "--tty": "true"},
+						commandArgs: []string{"sh"},
+					},
+					selectContainers: true,
+				},
+			},
+		},
+		{
+			name: "logs and exec are not used for other resources",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods + "," + kubernetesResourceService,
+			},
+			expectKeys: defaultExpectKeys,
+			want: map[string]expectAction{
+				"ctrl-o": {
+					action: &actionCli{
+						kubectl: &kubectl{resource: kubernetesResourcePods + "," + kubernetesResourceService},
+						action:  kubectlActions["describe"],
+						options: map[string]string{},
+					},
+				},
+			},
+		},
+		{
+			name: "keys of the navigation are not used with the navigation",
+			kubectl: &kubectl{
+				resource: "deployments",
+			},
+			navigate: true,
+			expectKeys: map[string]string{
+				"ctrl-o": "describe",
+				"ctrl-p": "print",
+				"ctrl-d": "",
+			},
+			want: map[string]expectAction{
+				"ctrl-p": {},
+			},
+		},
+		{
+			name: "no keys",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			expectKeys: map[string]string{},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := getExpectActions(tc.kubectl, tc.navigate, &resolvedConfig{expectKeys: tc.expectKeys})
+			assert.Equal(t, tc.want, got)
+			assert.NoError(t, gotErr)
+		})
+	}
+}
+
+func TestGetCli_Run_expect(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	testCases := []struct {
+		name             string
+		fzfOut           string
+		wantRunOperation string
+		wantRunResource  string
+		wantRunNames     []string
+		wantRunOptions   map[string]string
+		wantIOOut        string
+	}{
+		{
+			name:      "enter prints names",
+			fzfOut:    "\npod1 1/1 Running 2d\npod2 1/1 Running 2d\n",
+			wantIOOut: "pod1\npod2\n",
+		},
+		{
+			name:             "describe selected objects",
+			fzfOut:           "ctrl-o\npod1 1/1 Running 2d\npod2 1/1 Running 2d\n",
+			wantRunOperation: "describe",
+			wantRunResource:  kubernetesResourcePods,
+			wantRunNames:     []string{"pod1", "pod2"},
+			wantRunOptions:   map[string]string{},
+		},
+		{
+			name:             "follow logs of the container",
+			fzfOut:           "ctrl-f\npod1 1/1 Running 2d\n",
+			wantRunOperation: "logs",
+			wantRunNames:     []string{"pod1"},
+			wantRunOptions: map[string]string{
+				"-c":       "app",
+				"--follow": "true",
+			},
+		},
+		{
+			name:   "canceled",
+			fzfOut: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), "get", gomock.Any(), gomock.Any()).
+				Return([]byte("NAME READY STATUS AGE\npod1 1/1 Running 2d\npod2 1/1 Running 2d"), nil).
+				Times(1)
+			if tc.wantRunOperation == "logs" {
+				mockKubectl.EXPECT().
+					run(gomock.Any(), "get", []string{"pod1"}, map[string]string{"-o": "json"}).
+					Return([]byte(`{"spec": {"containers": [{"name": "app", "image": "app:1.0"}]}}`), nil).
+					Times(1)
+			}
+			if tc.wantRunOperation != "" {
+				mockKubectl.EXPECT().
+					runWithIO(gomock.Any(), tc.wantRunOperation, tc.wantRunResource, tc.wantRunNames, tc.wantRunOptions, nil, gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).
+					Times(1)
+			}
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				assert.Contains(t, strings.Join(args, " "), "--expect ctrl-f,ctrl-o,ctrl-x")
+				return []byte(tc.fzfOut), nil
+			}
+
+			sut, err := NewGetCli(&kubectl{resource: kubernetesResourcePods}, GetOptions{
+				PreviewFormat: kubectlOutputFormatDescribe,
+			})
+			require.NoError(t, err)
+			sut.kubectl = mockKubectl
+			for key, action := range sut.expectActions {
+				action.action.kubectl = mockKubectl
+				sut.expectActions[key] = action
+			}
+
+			var gotIOOut bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, &bytes.Buffer{})
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.wantIOOut, gotIOOut.String())
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+const (
+	// FinderAuto uses fzf if it's found in PATH, or the builtin finder otherwise
+	FinderAuto    = "auto"
+	FinderFzf     = "fzf"
+	FinderBuiltin = "builtin"
+)
+
+var (
+	// errorFinderCanceled is returned by the builtin finder when it's canceled, like fzf exits with 130
+	errorFinderCanceled = errors.New("the finder is canceled")
+	// errorFinderNoMatch is returned by the builtin finder when no items match the query, like fzf exits with 1
+	errorFinderNoMatch = errors.New("no items match the query")
+
+	lookPath = exec.LookPath
+)
+
+// finder runs fzf or the builtin finder to select items
+type finder struct {
+	builtin bool
+}
+
+// newFinder returns the finder of the name. fzf is used if the name is empty.
+func newFinder(name string) (finder, error) {
+	switch name {
+	case "", FinderFzf:
+		return finder{}, nil
+	case FinderBuiltin:
+		return finder{builtin: true}, nil
+	case FinderAuto:
+		if _, err := lookPath("fzf"); err != nil {
+			return finder{builtin: true}, nil
+		}
+		return finder{}, nil
+	}
+	return finder{}, fmt.Errorf("finder must be one of [%s, %s, %s]", FinderAuto, FinderFzf, FinderBuiltin)
+}
+
+// run runs the finder with args of fzf and ioIn as the list of candidates
+func (f finder) run(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+	if f.builtin {
+		return runBuiltinFinder(ctx, args, ioIn, ioErr)
+	}
+	return runCommandWithFzf(ctx, args, ioIn, ioErr)
+}
+
+// runFzf runs fzf with ioIn as the list of candidates.
+// fzf opens the terminal by itself for its UI when stdin is not a terminal.
+func runFzf(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "fzf", args...)
+	// Preview commands are written for sh instead of the user's shell
+	cmd.Env = append(os.Environ(), "SHELL=sh")
+	cmd.Stderr = ioErr
+	cmd.Stdin = ioIn
+	out, err := cmd.Output()
+	if errors.Is(err, exec.ErrNotFound) {
+		return nil, ErrFinderNotFound
+	}
+	return out, err
+}
+
+// isFinderCanceled returns true if fzf or the builtin finder is canceled by Ctrl-c or Esc
+func isFinderCanceled(err error) bool {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode() == 130
+	}
+	return errors.Is(err, errorFinderCanceled)
+}
+
+// isFinderNoMatch returns true if no items match the query on fzf or the builtin finder
+func isFinderNoMatch(err error) bool {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode() == 1
+	}
+	return errors.Is(err, errorFinderNoMatch)
+}
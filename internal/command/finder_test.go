@@ -0,0 +1,145 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFinder(t *testing.T) {
+	backupLookPath := lookPath
+	defer func() {
+		lookPath = backupLookPath
+	}()
+
+	testCases := []struct {
+		name       string
+		finder     string
+		lookPathFn func(file string) (string, error)
+		want       finder
+		wantErr    error
+	}{
+		{
+			name:   "fzf",
+			finder: FinderFzf,
+			want:   finder{},
+		},
+		{
+			name: "fzf by default",
+			want: finder{},
+		},
+		{
+			name:   "builtin",
+			finder: FinderBuiltin,
+			want:   finder{builtin: true},
+		},
+		{
+			name:   "auto with fzf",
+			finder: FinderAuto,
+			lookPathFn: func(file string) (string, error) {
+				return "/usr/bin/fzf", nil
+			},
+			want: finder{},
+		},
+		{
+			name:   "auto without fzf",
+			finder: FinderAuto,
+			lookPathFn: func(file string) (string, error) {
+				return "", errors.New("executable file not found in $PATH")
+			},
+			want: finder{builtin: true},
+		},
+		{
+			name:    "unknown finder",
+			finder:  "peco",
+			wantErr: fmt.Errorf("finder must be one of [auto, fzf, builtin]"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lookPath = tc.lookPathFn
+			got, gotErr := newFinder(tc.finder)
+			assert.Equal(t, tc.wantErr, gotErr)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestRunFzf_notFound(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+	_, gotErr := runFzf(context.Background(), nil, strings.NewReader(""), io.Discard)
+	assert.Equal(t, ErrFinderNotFound, gotErr)
+}
+
+func TestIsFinderCanceled(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "fzf is canceled",
+			err:  newExitError(t, 130),
+			want: true,
+		},
+		{
+			name: "fzf fails",
+			err:  newExitError(t, 2),
+			want: false,
+		},
+		{
+			name: "builtin finder is canceled",
+			err:  fmt.Errorf("failed to select: %w", errorFinderCanceled),
+			want: true,
+		},
+		{
+			name: "other error",
+			err:  errors.New("error"),
+			want: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isFinderCanceled(tc.err))
+		})
+	}
+}
+
+func TestIsFinderNoMatch(t *testing.T) {
+	testCases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{
+			name: "no match on fzf",
+			err:  newExitError(t, 1),
+			want: true,
+		},
+		{
+			name: "fzf is canceled",
+			err:  newExitError(t, 130),
+			want: false,
+		},
+		{
+			name: "no match on the builtin finder",
+			err:  errorFinderNoMatch,
+			want: true,
+		},
+		{
+			name: "other error",
+			err:  errors.New("error"),
+			want: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, isFinderNoMatch(tc.err))
+		})
+	}
+}
@@ -0,0 +1,266 @@
+package command
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// defaultFzfBindings are key bindings of fzf by default
+	defaultFzfBindings = []fzfBinding{
+		{key: "ctrl-k", action: "kill-line"},
+		{key: "ctrl-alt-t", action: "toggle-preview"},
+		{key: "ctrl-alt-n", action: "preview-down"},
+		{key: "ctrl-alt-p", action: "preview-up"},
+		{key: "ctrl-alt-v", action: "preview-page-down"},
+	}
+
+	// fzfActionWithArgumentRegexp matches an action taking the rest of a --bind value as its argument, like reload:command
+	fzfActionWithArgumentRegexp = regexp.MustCompile(`^[a-z-]+:`)
+	// fzfActionWithParenthesesRegexp matches an action taking an argument in parentheses, like reload(command)
+	fzfActionWithParenthesesRegexp = regexp.MustCompile(`^[a-z-]+\(`)
+)
+
+// fzfBinding is a key binding of fzf
+type fzfBinding struct {
+	key    string
+	action string
+}
+
+// fzfOptions are options of fzf rendered into arguments by args
+type fzfOptions struct {
+	inlineInfo    bool
+	multi         bool
+	layout        string
+	preview       string
+	previewWindow string
+	headerLines   int
+	header        string
+	query         string
+	selectOne     bool
+	exitZero      bool
+	expect        []string
+	bindings      []fzfBinding
+	// extraArgs are other arguments of fzf, which are rendered at last as they are
+	extraArgs []string
+}
+
+// newFzfOptions returns the default options of fzf with the preview command and the key bindings.
+// Options of KUBECTL_FZF_FZF_OPTION are merged on top of them.
+func newFzfOptions(previewCommand string, hasHeader bool, bindings []fzfBinding) (*fzfOptions, error) {
+	options := &fzfOptions{
+		inlineInfo:    true,
+		multi:         true,
+		layout:        "reverse",
+		preview:       previewCommand,
+		previewWindow: "down:70%",
+		bindings:      append([]fzfBinding{}, defaultFzfBindings...),
+	}
+	if hasHeader {
+		options.headerLines = 1
+	}
+	for _, binding := range bindings {
+		options.bind(binding.key, binding.action)
+	}
+
+	userOption := os.Getenv(envNameFzfOption)
+	if userOption == "" {
+		return options, nil
+	}
+	envVars := map[string]string{
+		// The preview command is expected to be enclosed by single quotes
+		"KUBECTL_FZF_FZF_PREVIEW_OPTION": strings.Replace(previewCommand, "'", `'\''`, -1),
+	}
+	var invalidEnvVars []string
+	userOption = os.Expand(userOption, func(envName string) string {
+		if value := envVars[envName]; value != "" {
+			return value
+		}
+		invalidEnvVars = append(invalidEnvVars, envName)
+		return ""
+	})
+	if len(invalidEnvVars) != 0 {
+		return nil, fmt.Errorf("%s has invalid environment variables: %s", envNameFzfOption, strings.Join(invalidEnvVars, ","))
+	}
+	args, err := splitArguments(userOption)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", envNameFzfOption, err)
+	}
+	if err := options.merge(args); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", envNameFzfOption, err)
+	}
+	return options, nil
+}
+
+// bind adds the key binding, replacing the one of the same key
+func (o *fzfOptions) bind(key string, action string) {
+	for i, binding := range o.bindings {
+		if binding.key == key {
+			o.bindings[i].action = action
+			return
+		}
+	}
+	o.bindings = append(o.bindings, fzfBinding{key: key, action: action})
+}
+
+// merge overrides options by fzf arguments. Unknown arguments are kept in extraArgs.
+func (o *fzfOptions) merge(args []string) error {
+	for i := 0; i < len(args); i++ {
+		name := args[i]
+		value, hasValue := "", false
+		if index := strings.Index(name, "="); strings.HasPrefix(name, "--") && index >= 0 {
+			name, value, hasValue = name[:index], name[index+1:], true
+		}
+		switch name {
+		case "--layout", "--preview", "--preview-window", "--header-lines", "--header", "-q", "--query", "--expect", "--bind":
+			if !hasValue {
+				if i+1 >= len(args) {
+					return fmt.Errorf("fzf option %s needs a value", name)
+				}
+				i++
+				value = args[i]
+			}
+		}
+
+		switch name {
+		case "--inline-info":
+			o.inlineInfo = true
+		case "--no-inline-info":
+			o.inlineInfo = false
+		case "-m", "--multi":
+			o.multi = true
+		case "+m", "--no-multi":
+			o.multi = false
+		case "--layout":
+			o.layout = value
+		case "--preview":
+			o.preview = value
+		case "--preview-window":
+			o.previewWindow = value
+		case "--header-lines":
+			headerLines, err := strconv.Atoi(value)
+			if err != nil || headerLines < 0 {
+				return fmt.Errorf("fzf option %s must be a number: %s", name, value)
+			}
+			o.headerLines = headerLines
+		case "--header":
+			o.header = value
+		case "-q", "--query":
+			o.query = value
+		case "-1", "--select-1":
+			o.selectOne = true
+		case "-0", "--exit-0":
+			o.exitZero = true
+		case "--expect":
+			o.expect = strings.Split(value, ",")
+		case "--bind":
+			for _, binding := range parseFzfBindings(value) {
+				o.bind(binding.key, binding.action)
+			}
+		default:
+			o.extraArgs = append(o.extraArgs, args[i])
+		}
+	}
+	return nil
+}
+
+// args returns the arguments of fzf. Each value is a separate argument, so it's not quoted.
+func (o fzfOptions) args() []string {
+	var args []string
+	if o.inlineInfo {
+		args = append(args, "--inline-info")
+	}
+	if o.multi {
+		args = append(args, "--multi")
+	} else {
+		args = append(args, "--no-multi")
+	}
+	if o.layout != "" {
+		args = append(args, "--layout", o.layout)
+	}
+	if o.preview != "" {
+		args = append(args, "--preview", o.preview)
+	}
+	if o.previewWindow != "" {
+		args = append(args, "--preview-window", o.previewWindow)
+	}
+	if o.headerLines > 0 {
+		args = append(args, "--header-lines", strconv.Itoa(o.headerLines))
+	}
+	if o.header != "" {
+		args = append(args, "--header", o.header)
+	}
+	if o.query != "" {
+		args = append(args, "--query", o.query)
+	}
+	if o.selectOne {
+		args = append(args, "--select-1")
+	}
+	if o.exitZero {
+		args = append(args, "--exit-0")
+	}
+	if len(o.expect) > 0 {
+		args = append(args, "--expect", strings.Join(o.expect, ","))
+	}
+	// Each binding is a separate argument, because an action like reload:command takes the rest of the value
+	for _, binding := range o.bindings {
+		args = append(args, "--bind", binding.key+":"+binding.action)
+	}
+	return append(args, o.extraArgs...)
+}
+
+// hasFzfExpect returns true if fzf prints the key of --expect on the 1st line of the output
+func hasFzfExpect(args []string) bool {
+	for _, arg := range args {
+		if arg == "--expect" || strings.HasPrefix(arg, "--expect=") {
+			return true
+		}
+	}
+	return false
+}
+
+// parseFzfBindings parses a --bind value like ctrl-k:kill-line,ctrl-r:reload:command.
+// An action with an argument after a colon takes the rest of the value.
+func parseFzfBindings(value string) []fzfBinding {
+	var bindings []fzfBinding
+	for value != "" {
+		// A key can be a comma or a colon itself
+		index := strings.Index(value[1:], ":") + 1
+		if index <= 0 {
+			break
+		}
+		key, action := value[:index], value[index+1:]
+		if fzfActionWithArgumentRegexp.MatchString(action) {
+			bindings = append(bindings, fzfBinding{key: key, action: action})
+			break
+		}
+		end := strings.Index(action, ",")
+		if fzfActionWithParenthesesRegexp.MatchString(action) {
+			if closing := strings.Index(action, ")"); closing >= 0 {
+				end = strings.Index(action[closing:], ",")
+				if end >= 0 {
+					end += closing
+				}
+			}
+		}
+		if end < 0 {
+			bindings = append(bindings, fzfBinding{key: key, action: action})
+			break
+		}
+		bindings = append(bindings, fzfBinding{key: key, action: action[:end]})
+		value = action[end+1:]
+	}
+	return bindings
+}
+
+// getFzfCommandLine returns the command line of fzf with quoted arguments for messages
+func getFzfCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = quoteArgument(arg)
+	}
+	return "fzf " + strings.Join(quoted, " ")
+}
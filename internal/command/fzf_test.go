@@ -0,0 +1,289 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// defaultFzfBindArgs are the arguments of the default key bindings, which are rendered after other options
+var defaultFzfBindArgs = []string{
+	"--bind", "ctrl-k:kill-line",
+	"--bind", "ctrl-alt-t:toggle-preview",
+	"--bind", "ctrl-alt-n:preview-down",
+	"--bind", "ctrl-alt-p:preview-up",
+	"--bind", "ctrl-alt-v:preview-page-down",
+}
+
+func TestNewFzfOptions(t *testing.T) {
+	testCases := []struct {
+		name           string
+		previewCommand string
+		hasHeader      bool
+		envVars        map[string]string
+		want           *fzfOptions
+		wantErr        error
+	}{
+		{
+			name:           "default options for multiple resources",
+			previewCommand: "kubectl describe {1}",
+			want: &fzfOptions{
+				inlineInfo:    true,
+				multi:         true,
+				layout:        "reverse",
+				preview:       "kubectl describe {1}",
+				previewWindow: "down:70%",
+				bindings:      defaultFzfBindings,
+			},
+		},
+		{
+			name:           "default options for single resource",
+			previewCommand: "kubectl describe pods {1}",
+			hasHeader:      true,
+			want: &fzfOptions{
+				inlineInfo:    true,
+				multi:         true,
+				layout:        "reverse",
+				preview:       "kubectl describe pods {1}",
+				previewWindow: "down:70%",
+				headerLines:   1,
+				bindings:      defaultFzfBindings,
+			},
+		},
+		{
+			name:           "merge KUBECTL_FZF_FZF_OPTION on top of default options",
+			previewCommand: "kubectl describe pods {1}",
+			hasHeader:      true,
+			envVars: map[string]string{
+				envNameFzfOption: "--no-multi --layout=default --preview-window 'right:50%' --bind ctrl-k:up,ctrl-j:down --height 40% --cycle -1 --exit-0",
+			},
+			want: &fzfOptions{
+				inlineInfo:    true,
+				multi:         false,
+				layout:        "default",
+				preview:       "kubectl describe pods {1}",
+				previewWindow: "right:50%",
+				headerLines:   1,
+				selectOne:     true,
+				exitZero:      true,
+				bindings: []fzfBinding{
+					{key: "ctrl-k", action: "up"},
+					{key: "ctrl-alt-t", action: "toggle-preview"},
+					{key: "ctrl-alt-n", action: "preview-down"},
+					{key: "ctrl-alt-p", action: "preview-up"},
+					{key: "ctrl-alt-v", action: "preview-page-down"},
+					{key: "ctrl-j", action: "down"},
+				},
+				extraArgs: []string{"--height", "40%", "--cycle"},
+			},
+		},
+		{
+			name:           "preview command with single quotes in KUBECTL_FZF_FZF_OPTION",
+			previewCommand: "kubectl get pods {1} '-o=jsonpath={.metadata.name}'",
+			envVars: map[string]string{
+				envNameFzfOption: "--preview 'echo; $KUBECTL_FZF_FZF_PREVIEW_OPTION'",
+			},
+			want: &fzfOptions{
+				inlineInfo:    true,
+				multi:         true,
+				layout:        "reverse",
+				preview:       "echo; kubectl get pods {1} '-o=jsonpath={.metadata.name}'",
+				previewWindow: "down:70%",
+				bindings:      defaultFzfBindings,
+			},
+		},
+		{
+			name:           "invalid env vars in KUBECTL_FZF_FZF_OPTION",
+			previewCommand: "unused preview command",
+			envVars: map[string]string{
+				envNameFzfOption: "--inline-info $UNKNOWN_ENV_NAME",
+			},
+			wantErr: fmt.Errorf("%s has invalid environment variables: UNKNOWN_ENV_NAME", envNameFzfOption),
+		},
+		{
+			name:           "unterminated quote in KUBECTL_FZF_FZF_OPTION",
+			previewCommand: "unused preview command",
+			envVars: map[string]string{
+				envNameFzfOption: "--header 'unterminated",
+			},
+			wantErr: fmt.Errorf("failed to parse %s: %w", envNameFzfOption, errorUnterminatedQuote),
+		},
+		{
+			name:           "no value in KUBECTL_FZF_FZF_OPTION",
+			previewCommand: "unused preview command",
+			envVars: map[string]string{
+				envNameFzfOption: "--query",
+			},
+			wantErr: fmt.Errorf("failed to parse %s: %w", envNameFzfOption, errors.New("fzf option --query needs a value")),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				for k := range tc.envVars {
+					require.NoError(t, os.Unsetenv(k))
+				}
+			}()
+			for k, v := range tc.envVars {
+				require.NoError(t, os.Setenv(k, v))
+			}
+			got, gotErr := newFzfOptions(tc.previewCommand, tc.hasHeader, nil)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+func TestFzfOptions_args(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options fzfOptions
+		want    []string
+	}{
+		{
+			name: "all options",
+			options: fzfOptions{
+				inlineInfo:    true,
+				multi:         true,
+				layout:        "reverse",
+				preview:       "kubectl get pods {1} '-o=jsonpath={.metadata.name}'",
+				previewWindow: "down:70%",
+				headerLines:   1,
+				header:        "ctrl-o: owned objects, ctrl-b: back",
+				query:         "foo bar",
+				selectOne:     true,
+				exitZero:      true,
+				expect:        []string{"ctrl-o", "ctrl-b"},
+				bindings: []fzfBinding{
+					{key: "ctrl-k", action: "kill-line"},
+					{key: "ctrl-r", action: "reload:kubectl get pods,svc --no-headers=true"},
+				},
+				extraArgs: []string{"--track"},
+			},
+			want: []string{
+				"--inline-info",
+				"--multi",
+				"--layout", "reverse",
+				"--preview", "kubectl get pods {1} '-o=jsonpath={.metadata.name}'",
+				"--preview-window", "down:70%",
+				"--header-lines", "1",
+				"--header", "ctrl-o: owned objects, ctrl-b: back",
+				"--query", "foo bar",
+				"--select-1",
+				"--exit-0",
+				"--expect", "ctrl-o,ctrl-b",
+				"--bind", "ctrl-k:kill-line",
+				"--bind", "ctrl-r:reload:kubectl get pods,svc --no-headers=true",
+				"--track",
+			},
+		},
+		{
+			name:    "no options",
+			options: fzfOptions{},
+			want:    []string{"--no-multi"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, tc.options.args())
+		})
+	}
+}
+
+func TestFzfOptions_bind(t *testing.T) {
+	sut := fzfOptions{
+		bindings: []fzfBinding{
+			{key: "ctrl-k", action: "kill-line"},
+		},
+	}
+	sut.bind("ctrl-r", "reload:kubectl get pods")
+	sut.bind("ctrl-k", "up")
+	assert.Equal(t, []fzfBinding{
+		{key: "ctrl-k", action: "up"},
+		{key: "ctrl-r", action: "reload:kubectl get pods"},
+	}, sut.bindings)
+}
+
+func TestHasFzfExpect(t *testing.T) {
+	testCases := []struct {
+		name string
+		args []string
+		want bool
+	}{
+		{
+			name: "expect with the value as the next argument",
+			args: []string{"--multi", "--expect", "ctrl-o,ctrl-b"},
+			want: true,
+		},
+		{
+			name: "expect with =",
+			args: []string{"--expect=alt-x"},
+			want: true,
+		},
+		{
+			name: "no expect",
+			args: []string{"--filter", "pod"},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, hasFzfExpect(tc.args))
+		})
+	}
+}
+
+func TestParseFzfBindings(t *testing.T) {
+	testCases := []struct {
+		name  string
+		value string
+		want  []fzfBinding
+	}{
+		{
+			name:  "actions without arguments",
+			value: "ctrl-k:kill-line,ctrl-alt-t:toggle-preview",
+			want: []fzfBinding{
+				{key: "ctrl-k", action: "kill-line"},
+				{key: "ctrl-alt-t", action: "toggle-preview"},
+			},
+		},
+		{
+			name:  "an action with an argument takes the rest",
+			value: "ctrl-k:kill-line,ctrl-r:reload:kubectl get pods,svc",
+			want: []fzfBinding{
+				{key: "ctrl-k", action: "kill-line"},
+				{key: "ctrl-r", action: "reload:kubectl get pods,svc"},
+			},
+		},
+		{
+			name:  "an action with an argument in parentheses",
+			value: "ctrl-r:reload(kubectl get pods,svc),ctrl-k:kill-line",
+			want: []fzfBinding{
+				{key: "ctrl-r", action: "reload(kubectl get pods,svc)"},
+				{key: "ctrl-k", action: "kill-line"},
+			},
+		},
+		{
+			name:  "comma and colon keys",
+			value: ",:jump,::accept",
+			want: []fzfBinding{
+				{key: ",", action: "jump"},
+				{key: ":", action: "accept"},
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseFzfBindings(tc.value))
+		})
+	}
+}
+
+func TestGetFzfCommandLine(t *testing.T) {
+	got := getFzfCommandLine([]string{"--query", "foo bar", "--preview", "kubectl get pods {1} '-o=jsonpath={.metadata.name}'", "--multi"})
+	assert.Equal(t, `fzf --query 'foo bar' --preview 'kubectl get pods {1} '\''-o=jsonpath={.metadata.name}'\''' --multi`, got)
+}
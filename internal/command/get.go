@@ -3,101 +3,561 @@ package command
 import (
 	"bytes"
 	"context"
-	"errors"
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"os/exec"
+	"net"
+	"os"
+	"regexp"
+	"strconv"
 	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
 
+const (
+	// kubectlNoResourcesMessage is shown by kubectl get if no objects are found
+	kubectlNoResourcesMessage = "No resources found"
+	// kubectlUnknownResourceMessage is shown by kubectl get and client-go for an unknown resource type
+	kubectlUnknownResourceMessage = "the server doesn't have a resource type"
+
+	// fzfListenHost is the host of fzf --listen-unsafe for the watch mode
+	fzfListenHost = "localhost"
+	// fzfListenAddress lets fzf choose a port which is not used
+	fzfListenAddress = fzfListenHost + ":0"
+)
+
+var kubectlNoResourcesInNamespace = regexp.MustCompile(`^No resources found in (\S+) namespace\.$`)
+
 type getCli struct {
-	kubectl    Kubectl
-	getOptions map[string]string
-	fzfOption  string
-}
-
-var (
-	errorInvalidArgumentFZFPreviewCommand = errors.New("preview format must be one of [describe, yaml]")
-
-	getCliPreviewCommands = map[string]struct {
-		operation string
-		options   map[string]string
-	}{
-		kubectlOutputFormatDescribe: {
-			operation: "describe",
-		},
-		kubectlOutputFormatYaml: {
-			operation: "get",
-			options: map[string]string{
-				"-o": "yaml",
-			},
-		},
+	kubectl       Kubectl
+	getOptions    map[string]string
+	finder        finder
+	fzfArgs       []string
+	allNamespaces bool
+	output        *output
+	// watchInterval is the interval to reload objects on fzf. The watch mode is disabled if it's 0
+	watchInterval time.Duration
+	// reloadAction is the fzf action to reload objects by the key and in the watch mode
+	reloadAction string
+	// selectContainers is true to select a container of each selected pod
+	selectContainers bool
+	// navigation is not nil if owned objects and owners of an object can be listed by keys
+	navigation *navigation
+	// names are the names of objects to list. All objects are listed if it's empty
+	names []string
+	// namespace is the namespace of --namespace, which is empty for the current namespace or all namespaces
+	namespace string
+	// namespaceKubectl gets the namespace to check if it exists when no objects are found.
+	// It's nil across all namespaces.
+	namespaceKubectl Kubectl
+	// expectActions are actions run on selected objects by keys of fzf --expect
+	expectActions map[string]expectAction
+	// filter is true to select objects matching the query without the interaction
+	filter    bool
+	selectOne bool
+	// config is used to select containers and objects listed by the navigation
+	config *resolvedConfig
+}
+
+// SelectOptions are options to select objects on the finder
+type SelectOptions struct {
+	// Query is the initial query on the finder, or the query to filter objects
+	Query string
+	// Filter is true to select all objects matching the query without the interaction, like fzf --filter
+	Filter bool
+	// SelectOne is true to select the object automatically if it's the only one matching the query.
+	// With Filter, it's an error if multiple objects match the query.
+	SelectOne bool
+	// ExitZero is true to exit without the interaction if no objects match the query
+	ExitZero bool
+}
+
+// resourceObject is an object selected on fzf.
+// namespace is empty unless objects are listed across all namespaces.
+type resourceObject struct {
+	namespace string
+	name      string
+	// container is the name of the container selected for a pod
+	container string
+}
+
+// withContainer returns s with "-c container" for kubectl if the container is selected
+func (o resourceObject) withContainer(s string) string {
+	if o.container == "" {
+		return s
 	}
-)
+	return s + " -c " + o.container
+}
+
+func (o resourceObject) String() string {
+	if o.namespace == "" {
+		return o.name
+	}
+	return o.namespace + "/" + o.name
+}
+
+// groupByNamespace returns names of objects for each namespace, because objects in different namespaces cannot be passed to kubectl at once.
+// namespaces are in the order of objects.
+func groupByNamespace(objects []resourceObject) ([]string, map[string][]string) {
+	var namespaces []string
+	namesByNamespace := map[string][]string{}
+	for _, object := range objects {
+		if _, ok := namesByNamespace[object.namespace]; !ok {
+			namespaces = append(namespaces, object.namespace)
+		}
+		namesByNamespace[object.namespace] = append(namesByNamespace[object.namespace], object.name)
+	}
+	return namespaces, namesByNamespace
+}
+
+// GetOptions are options to list and select objects on fzf
+type GetOptions struct {
+	// PreviewFormat is the format of the preview, like describe or yaml
+	PreviewFormat string
+	SelectOptions SelectOptions
+	// OutputFormat is the format of selected objects. The default format is used if it's empty
+	OutputFormat string
+	// NulDelimited is true to separate selected objects by NUL instead of newline
+	NulDelimited bool
+	// WatchInterval is the interval to reload objects on fzf. The watch mode is disabled if it's 0
+	WatchInterval time.Duration
+	// KubectlOptions are passed to kubectl get to list objects, like --selector
+	KubectlOptions map[string]string
+	// SelectContainers is true to select a container of each selected pod
+	SelectContainers bool
+	// Navigate is true to list owned objects and owners of an object on fzf by keys
+	Navigate bool
+	// Config is the config file, like keys of bindings. The default config is used if it's nil
+	Config *Config
+	// LogsPreview are options of the logs preview. The default options are used if it's nil
+	LogsPreview *LogsPreviewOptions
+	// Finder is one of FinderAuto, FinderFzf or FinderBuiltin. fzf is used if it's empty
+	Finder string
+}
 
-func NewGetCli(k *kubectl, previewFormat string, fzfQuery string) (*getCli, error) {
-	previewCommandTemplate, ok := getCliPreviewCommands[previewFormat]
-	if !ok {
-		return nil, errorInvalidArgumentFZFPreviewCommand
+// NewGetCli returns the cli to select objects on fzf.
+// It returns ErrNoMatch on Run if no objects match the query with options.SelectOptions.Filter or ExitZero.
+func NewGetCli(backend kubectlBackend, options GetOptions) (*getCli, error) {
+	config, err := newResolvedConfig(options.Config, options.LogsPreview)
+	if err != nil {
+		return nil, err
+	}
+	return newGetCli(backend, nil, options, config)
+}
+
+// newGetCli returns the cli to select objects of the names on fzf. All objects are listed if names are empty.
+// Config and LogsPreview of options are not used, but the resolved config is.
+func newGetCli(backend kubectlBackend, names []string, options GetOptions, config *resolvedConfig) (*getCli, error) {
+	k := backend.resourceKubectl()
+	if options.WatchInterval < 0 {
+		return nil, errorInvalidArgumentWatchInterval
+	}
+	if options.SelectOptions.Filter && (options.WatchInterval > 0 || options.Navigate) {
+		return nil, errorInvalidArgumentFilter
+	}
+	finder, err := newFinder(options.Finder)
+	if err != nil {
+		return nil, err
+	}
+	if options.SelectContainers && !isPodResource(k) {
+		return nil, errorInvalidArgumentSelectContainers
+	}
+	if options.SelectContainers && options.Navigate {
+		return nil, errorInvalidArgumentNavigation
+	}
+	previewCommand, err := getPreviewCommand(k, options.PreviewFormat, config)
+	if err != nil {
+		return nil, err
+	}
+	output, err := newOutput(options.OutputFormat, k.allNamespaces, options.NulDelimited)
+	if err != nil {
+		return nil, err
+	}
+	if options.SelectContainers && output.format != outputFormatName && output.format != outputFormatNamespaceName {
+		return nil, errorInvalidArgumentSelectContainers
 	}
 
-	resource := k.resource
 	var getOptions map[string]string
-	hasMultipleResources := false
-	if k.resource == kubernetesResourceAll || strings.Contains(k.resource, ",") {
-		resource = ""
-		getOptions = map[string]string{
-			"--no-headers": "true",
+	if len(options.KubectlOptions) > 0 {
+		getOptions = make(map[string]string, len(options.KubectlOptions))
+		for k, v := range options.KubectlOptions {
+			getOptions[k] = v
 		}
-		hasMultipleResources = true
 	}
-	previewCommand := k.getCommand(previewCommandTemplate.operation, resource, []string{"{1}"}, previewCommandTemplate.options)
-	fzfOption, err := getFzfOption(previewCommand, hasMultipleResources)
+	hasMultipleResources := k.hasMultipleResources()
+	if hasMultipleResources {
+		if getOptions == nil {
+			getOptions = map[string]string{}
+		}
+		getOptions["--no-headers"] = "true"
+	}
+	if k.allNamespaces {
+		if getOptions == nil {
+			getOptions = map[string]string{}
+		}
+		getOptions["--all-namespaces"] = "true"
+	}
+	reloadCommand := k.getCommand("get", k.resource, names, getOptions)
+	bindings, err := getBindings(k, options.PreviewFormat, previewCommand, reloadCommand, config)
+	if err != nil {
+		return nil, err
+	}
+	fzfOptions, err := newFzfOptions(previewCommand, !hasMultipleResources, append(bindings, config.fzfBindings...))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get fzf option: %w", err)
 	}
-	if fzfQuery != "" {
-		fzfOption = fzfOption + " --query " + fzfQuery
+	fzfOptions.query = options.SelectOptions.Query
+	fzfOptions.selectOne = options.SelectOptions.SelectOne
+	fzfOptions.exitZero = options.SelectOptions.ExitZero
+	if options.WatchInterval > 0 {
+		// Selections and the cursor are kept across reloads by the names of objects, since other columns like STATUS change
+		idFields := "1"
+		if k.allNamespaces {
+			idFields = "1,2"
+		}
+		fzfOptions.extraArgs = append(fzfOptions.extraArgs, "--track", "--id-nth", idFields)
+	}
+	var namespaceKubectl Kubectl
+	if !k.allNamespaces {
+		namespaceKubectl = backend.withKubectl(&kubectl{
+			resource:    "namespaces",
+			kubeContext: k.kubeContext,
+		})
+	}
+	var nav *navigation
+	if options.Navigate {
+		nav = &navigation{
+			backend:       backend,
+			previewFormat: options.PreviewFormat,
+		}
+		fzfOptions.expect = append(fzfOptions.expect, navigationKeyOwned, navigationKeyOwners)
+		fzfOptions.header = navigationHeader
+	}
+	expectActions, err := getExpectActions(backend, options.Navigate, config)
+	if err != nil {
+		return nil, err
+	}
+	fzfOptions.expect = append(fzfOptions.expect, getExpectKeys(expectActions)...)
+	fzfArgs := fzfOptions.args()
+	if options.SelectOptions.Filter {
+		// Keys and other options are not used without the interaction
+		expectActions = nil
+		fzfArgs = []string{"--filter", options.SelectOptions.Query}
+		if fzfOptions.headerLines > 0 {
+			fzfArgs = append(fzfArgs, "--header-lines", strconv.Itoa(fzfOptions.headerLines))
+		}
 	}
 
 	return &getCli{
-		kubectl:    k,
-		getOptions: getOptions,
-		fzfOption:  fzfOption,
+		kubectl:          backend,
+		getOptions:       getOptions,
+		finder:           finder,
+		fzfArgs:          fzfArgs,
+		allNamespaces:    k.allNamespaces,
+		output:           output,
+		watchInterval:    options.WatchInterval,
+		reloadAction:     "reload:" + reloadCommand,
+		selectContainers: options.SelectContainers,
+		navigation:       nav,
+		names:            names,
+		namespace:        k.namespace,
+		namespaceKubectl: namespaceKubectl,
+		expectActions:    expectActions,
+		filter:           options.SelectOptions.Filter,
+		selectOne:        options.SelectOptions.SelectOne,
+		config:           config,
 	}, nil
 }
 
 func (c getCli) Run(ctx context.Context, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
-	out, err := c.kubectl.run(ctx, "get", nil, c.getOptions)
+	cli := &c
+	var objects []resourceObject
+	var key string
+	var err error
+	if c.navigation != nil {
+		cli, objects, key, err = c.navigate(ctx, ioErr)
+	} else {
+		objects, key, err = c.selectObjectsWithKey(ctx, ioErr)
+	}
 	if err != nil {
 		return err
 	}
-	if len(strings.Split(strings.TrimSpace(string(out)), "\n")) == 1 {
-		return fmt.Errorf("failed to run kubectl. Namespace may not exist")
+	if action, ok := cli.expectActions[key]; ok {
+		return cli.runExpectAction(ctx, action, objects, ioIn, ioOut, ioErr)
 	}
-	command := fmt.Sprintf("echo '%s' | fzf %s", string(out), c.fzfOption)
-	out, err = runCommandWithFzf(ctx, command, ioIn, ioErr)
+	if len(objects) == 0 {
+		return nil
+	}
+	return cli.printObjects(ctx, objects, ioOut)
+}
+
+// printObjects writes the objects in the output format
+func (c getCli) printObjects(ctx context.Context, objects []resourceObject, ioOut io.Writer) error {
+	out, err := c.output.formatObjects(ctx, c.kubectl, objects)
 	if err != nil {
-		if exitErr, ok := err.(*exec.ExitError); ok {
-			// Script canceled by Ctrl-c
-			// Only for bash?: http://tldp.org/LDP/abs/html/exitcodes.html
-			if exitErr.ExitCode() == 130 {
-				return nil
-			}
+		return err
+	}
+	if _, err := ioOut.Write(out); err != nil {
+		return fmt.Errorf("failed to output the result: %w", err)
+	}
+	return nil
+}
+
+// selectObjects returns the objects selected on fzf.
+// It returns ErrCanceled if fzf is canceled.
+func (c getCli) selectObjects(ctx context.Context, ioErr io.Writer) ([]resourceObject, error) {
+	objects, _, err := c.selectObjectsWithKey(ctx, ioErr)
+	return objects, err
+}
+
+// selectObjectsWithKey returns the objects selected on fzf and the key of --expect to select them.
+// The key is empty for enter.
+func (c getCli) selectObjectsWithKey(ctx context.Context, ioErr io.Writer) ([]resourceObject, string, error) {
+	out, err := c.listObjects(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	fzfArgs := c.fzfArgs
+	if c.watchInterval > 0 {
+		listener, err := newFzfListener()
+		if err != nil {
+			return nil, "", err
 		}
-		return fmt.Errorf("failed to run the command %s: %w", command, err)
+		defer listener.close()
+		fzfArgs = append(append([]string{}, c.fzfArgs...), listener.fzfArgs()...)
+		restoreEnv := setEnv(envNameFzfAPIKey, listener.apiKey)
+		defer restoreEnv()
+
+		watchCtx, cancel := context.WithCancel(ctx)
+		watchDone := make(chan struct{})
+		go func() {
+			defer close(watchDone)
+			c.watch(watchCtx, listener, ioErr)
+		}()
+		defer func() {
+			cancel()
+			<-watchDone
+		}()
+	}
+	out, err = c.finder.run(ctx, fzfArgs, bytes.NewReader(out), ioErr)
+	if err != nil {
+		if isFinderCanceled(err) {
+			return nil, "", ErrCanceled
+		}
+		if isFinderNoMatch(err) {
+			return nil, "", ErrNoMatch
+		}
+		return nil, "", fmt.Errorf("failed to run the command %s: %w", getFzfCommandLine(fzfArgs), err)
 	}
 
-	rows := strings.Split(strings.TrimSpace(string(out)), "\n")
-	names := make([]string, len(rows))
-	for i, row := range rows {
+	rows := string(out)
+	var key string
+	if hasFzfExpect(fzfArgs) {
+		// The 1st line is the key of --expect, which is empty for enter
+		key, rows = splitFzfExpectedKey(rows)
+	}
+	var objects []resourceObject
+	for _, row := range strings.Split(strings.TrimSpace(rows), "\n") {
 		columns := strings.Fields(row)
-		names[i] = strings.TrimSpace(columns[0])
+		if c.allNamespaces {
+			if len(columns) < 2 {
+				continue
+			}
+			objects = append(objects, resourceObject{
+				namespace: columns[0],
+				name:      columns[1],
+			})
+			continue
+		}
+		if len(columns) == 0 {
+			continue
+		}
+		objects = append(objects, resourceObject{
+			name: columns[0],
+		})
 	}
+	if c.filter && c.selectOne && len(objects) > 1 {
+		return nil, "", fmt.Errorf("%w: %d objects", errorMultipleMatches, len(objects))
+	}
+	if c.selectContainers {
+		for i, object := range objects {
+			container, err := c.selectContainer(ctx, object, ioErr)
+			if err != nil {
+				return nil, "", err
+			}
+			if container == "" {
+				return nil, "", nil
+			}
+			objects[i].container = container
+		}
+	}
+	return objects, key, nil
+}
 
-	out = bytes.NewBufferString(strings.Join(names, "\n") + "\n").Bytes()
-	if _, err := ioOut.Write(out); err != nil {
-		return fmt.Errorf("failed to output the result: %w", err)
+// listObjects returns the output of kubectl get to list objects.
+// It returns an error for the reason like ErrNoObjects or ErrForbidden if there are no objects to select.
+func (c getCli) listObjects(ctx context.Context) ([]byte, error) {
+	out, err := c.kubectl.run(ctx, "get", c.names, c.getOptions)
+	if err != nil {
+		return nil, getListError(err)
+	}
+	message := strings.TrimSpace(string(out))
+	if message != "" && !strings.HasPrefix(message, kubectlNoResourcesMessage) {
+		return out, nil
+	}
+
+	// kubectl shows the same message for a namespace which doesn't exist
+	namespace := c.namespace
+	if matches := kubectlNoResourcesInNamespace.FindStringSubmatch(message); namespace == "" && matches != nil {
+		namespace = matches[1]
+	}
+	if namespace != "" && c.namespaceKubectl != nil {
+		_, err := c.namespaceKubectl.run(ctx, "get", []string{namespace}, map[string]string{"-o": "name"})
+		if isNotFound(err) {
+			return nil, &listError{
+				reason:  ErrNamespaceNotFound,
+				message: fmt.Sprintf("namespace %q does not exist", namespace),
+			}
+		}
+	}
+	if message == "" {
+		message = kubectlNoResourcesMessage
+		if namespace != "" {
+			message = fmt.Sprintf("%s in %s namespace.", kubectlNoResourcesMessage, namespace)
+		}
+	}
+	return nil, &listError{
+		reason:  ErrNoObjects,
+		message: message,
+	}
+}
+
+// getListError returns the error for the reason why kubectl get fails, or err itself for other reasons
+func getListError(err error) error {
+	message := strings.TrimSpace(err.Error())
+	switch {
+	case strings.Contains(message, kubectlUnknownResourceMessage):
+		return &listError{
+			reason:  ErrUnknownResource,
+			message: strings.TrimPrefix(message, "error: ") + ". Run kubectl api-resources to see resource types",
+			err:     err,
+		}
+	case apierrors.IsForbidden(err) || strings.Contains(message, "(Forbidden)"):
+		return &listError{
+			reason:  ErrForbidden,
+			message: "permission denied by RBAC: " + strings.TrimPrefix(message, "Error from server (Forbidden): "),
+			err:     err,
+		}
+	}
+	return err
+}
+
+// isNotFound returns true if kubectl or the API server returns NotFound for the object
+func isNotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	return apierrors.IsNotFound(err) || strings.Contains(err.Error(), "(NotFound)")
+}
+
+// watch reloads objects on fzf every interval until ctx is done.
+// The watch mode ends if fzf rejects the reload, with the error on ioErr.
+func (c getCli) watch(ctx context.Context, listener *fzfListener, ioErr io.Writer) {
+	ticker := time.NewTicker(c.watchInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			address, err := listener.address()
+			if err != nil {
+				fmt.Fprintf(ioErr, "the watch mode is stopped: %v\n", err)
+				return
+			}
+			if address == "" {
+				// fzf doesn't listen yet
+				continue
+			}
+			if err := postFzfAction(ctx, address, listener.apiKey, c.reloadAction); err != nil {
+				if ctx.Err() != nil {
+					// fzf already exits
+					return
+				}
+				fmt.Fprintf(ioErr, "the watch mode is stopped: %v\n", err)
+				return
+			}
+		}
+	}
+}
+
+// fzfListener is the server of fzf --listen-unsafe on a port chosen by fzf.
+// fzf writes the port into portFile on the start event, and requests are authenticated by apiKey.
+type fzfListener struct {
+	portFile string
+	apiKey   string
+}
+
+func newFzfListener() (*fzfListener, error) {
+	file, err := os.CreateTemp("", "kubectl-fzf-port-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create a file for the port of fzf: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return nil, fmt.Errorf("failed to create a file for the port of fzf: %w", err)
+	}
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		_ = os.Remove(file.Name())
+		return nil, fmt.Errorf("failed to generate the API key of fzf: %w", err)
+	}
+	return &fzfListener{
+		portFile: file.Name(),
+		apiKey:   hex.EncodeToString(key),
+	}, nil
+}
+
+// fzfArgs returns fzf options to listen on a port chosen by fzf and write it into portFile.
+// Reload actions are accepted by --listen-unsafe instead of --listen since fzf 0.48.
+func (l fzfListener) fzfArgs() []string {
+	return []string{
+		"--listen-unsafe=" + fzfListenAddress,
+		"--bind", "start:" + getFzfAction("execute-silent", "echo $FZF_PORT > "+quoteArgument(l.portFile)),
+	}
+}
+
+// address returns the address of fzf, or an empty string if fzf doesn't write the port yet
+func (l fzfListener) address() (string, error) {
+	out, err := os.ReadFile(l.portFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the port of fzf: %w", err)
+	}
+	port := strings.TrimSpace(string(out))
+	if port == "" {
+		return "", nil
+	}
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", fmt.Errorf("invalid port of fzf: %s", port)
+	}
+	return net.JoinHostPort(fzfListenHost, port), nil
+}
+
+func (l fzfListener) close() {
+	_ = os.Remove(l.portFile)
+}
+
+// setEnv sets the environment variable, and returns the function to restore it
+func setEnv(name string, value string) func() {
+	previous, ok := os.LookupEnv(name)
+	_ = os.Setenv(name, value)
+	return func() {
+		if ok {
+			_ = os.Setenv(name, previous)
+		} else {
+			_ = os.Unsetenv(name)
+		}
 	}
-	return nil
 }
@@ -8,12 +8,16 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"regexp"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 )
 
 const (
@@ -22,55 +26,121 @@ const (
 )
 
 func TestNewGetCli(t *testing.T) {
-	fzfOptionFunc := func(previewCommand string, hasMultipleResources bool, query string) string {
-		fzf, err := getFzfOption(previewCommand, hasMultipleResources)
-		if err != nil {
-			panic(err)
+	// Other bindings are tested by TestGetBindings, and keys of --expect are tested by TestGetExpectActions
+	config := &Config{
+		ActionKeys: map[string]string{
+			bindingLogs:               "",
+			bindingExec:               "",
+			bindingDelete:             "",
+			bindingTogglePreview:      "",
+			bindingTogglePreviousLogs: "",
+			bindingRevealSecret:       "",
+		},
+		ExpectKeys: map[string]string{
+			"ctrl-o": "",
+			"ctrl-f": "",
+			"ctrl-x": "",
+		},
+	}
+	resolved, err := newResolvedConfig(config, nil)
+	require.NoError(t, err)
+
+	// fzfArgsFunc returns arguments of fzf with default options, and args are inserted before key bindings
+	fzfArgsFunc := func(previewCommand string, reloadCommand string, hasMultipleResources bool, args ...string) []string {
+		fzfArgs := []string{
+			"--inline-info",
+			"--multi",
+			"--layout", "reverse",
+			"--preview", previewCommand,
+			"--preview-window", "down:70%",
 		}
-		if query != "" {
-			return fzf + " --query " + query
+		if !hasMultipleResources {
+			fzfArgs = append(fzfArgs, "--header-lines", "1")
 		}
-		return fzf
+		fzfArgs = append(fzfArgs, args...)
+		fzfArgs = append(fzfArgs, defaultFzfBindArgs...)
+		return append(fzfArgs, "--bind", "ctrl-r:reload:"+reloadCommand)
 	}
 
 	testCases := []struct {
-		name           string
-		resource       string
-		namespace      string
-		previewCommand string
-		outputFormat   string
-		fzfQuery       string
-		envVars        map[string]string
-		want           *getCli
-		wantErr        error
+		name             string
+		resource         string
+		namespace        string
+		allNamespaces    bool
+		previewCommand   string
+		outputFormat     string
+		selectOptions    SelectOptions
+		nulDelimited     bool
+		watchInterval    time.Duration
+		options          map[string]string
+		envVars          map[string]string
+		selectContainers bool
+		navigate         bool
+		finder           string
+		want             *getCli
+		wantErr          error
 	}{
 		{
 			name:           "desc preview command for single resource",
 			resource:       kubernetesResourcePods,
 			namespace:      "default",
 			previewCommand: kubectlOutputFormatDescribe,
-			fzfQuery:       "",
 			want: &getCli{
+				config: resolved,
 				kubectl: &kubectl{
 					resource:  kubernetesResourcePods,
 					namespace: "default",
 				},
-				fzfOption: fzfOptionFunc("kubectl describe pods {1} -n=default", false, ""),
+				fzfArgs:          fzfArgsFunc("kubectl describe pods {1} -n=default", "kubectl get pods -n=default", false),
+				reloadAction:     "reload:kubectl get pods -n=default",
+				namespace:        "default",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
 			wantErr: nil,
 		},
+		{
+			name:           "builtin finder",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			finder:         FinderBuiltin,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				finder:           finder{builtin: true},
+				fzfArgs:          fzfArgsFunc("kubectl describe pods {1}", "kubectl get pods", false),
+				reloadAction:     "reload:kubectl get pods",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+			},
+		},
 		{
 			name:           "desc preview command for all resources",
 			resource:       kubernetesResourceAll,
 			previewCommand: kubectlOutputFormatDescribe,
 			want: &getCli{
+				config: resolved,
 				kubectl: &kubectl{
 					resource: kubernetesResourceAll,
 				},
 				getOptions: map[string]string{
 					"--no-headers": "true",
 				},
-				fzfOption: fzfOptionFunc("kubectl describe {1}", true, ""),
+				fzfArgs:          fzfArgsFunc("kubectl describe {1}", "kubectl get all --no-headers=true", true),
+				reloadAction:     "reload:kubectl get all --no-headers=true",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
 			wantErr: nil,
 		},
@@ -78,18 +148,339 @@ func TestNewGetCli(t *testing.T) {
 			name:           "get yaml preview command for multiple resources",
 			resource:       kubernetesResourcePods + "," + kubernetesResourceService,
 			previewCommand: kubectlOutputFormatYaml,
-			fzfQuery:       "svc",
+			selectOptions:  SelectOptions{Query: "svc 'api"},
 			want: &getCli{
+				config: resolved,
 				kubectl: &kubectl{
 					resource: kubernetesResourcePods + "," + kubernetesResourceService,
 				},
 				getOptions: map[string]string{
 					"--no-headers": "true",
 				},
-				fzfOption: fzfOptionFunc("kubectl get {1} -o=yaml", true, "svc"),
+				fzfArgs:          fzfArgsFunc("kubectl get {1} -o=yaml", "kubectl get pods,svc --no-headers=true", true, "--query", "svc 'api"),
+				reloadAction:     "reload:kubectl get pods,svc --no-headers=true",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
 			wantErr: nil,
 		},
+		{
+			name:           "desc preview command for single resource across all namespaces",
+			resource:       kubernetesResourcePods,
+			allNamespaces:  true,
+			previewCommand: kubectlOutputFormatDescribe,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource:      kubernetesResourcePods,
+					allNamespaces: true,
+				},
+				getOptions: map[string]string{
+					"--all-namespaces": "true",
+				},
+				fzfArgs:       fzfArgsFunc("kubectl describe pods {2} -n={1}", "kubectl get pods --all-namespaces=true", false),
+				reloadAction:  "reload:kubectl get pods --all-namespaces=true",
+				allNamespaces: true,
+				output: &output{
+					format:    outputFormatNamespaceName,
+					delimiter: "\n",
+				},
+			},
+		},
+		{
+			name:           "get yaml preview command for multiple resources across all namespaces",
+			resource:       kubernetesResourcePods + "," + kubernetesResourceService,
+			allNamespaces:  true,
+			previewCommand: kubectlOutputFormatYaml,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource:      kubernetesResourcePods + "," + kubernetesResourceService,
+					allNamespaces: true,
+				},
+				getOptions: map[string]string{
+					"--no-headers":     "true",
+					"--all-namespaces": "true",
+				},
+				fzfArgs:       fzfArgsFunc("kubectl get {2} -n={1} -o=yaml", "kubectl get pods,svc --all-namespaces=true --no-headers=true", true),
+				reloadAction:  "reload:kubectl get pods,svc --all-namespaces=true --no-headers=true",
+				allNamespaces: true,
+				output: &output{
+					format:    outputFormatNamespaceName,
+					delimiter: "\n",
+				},
+			},
+		},
+		{
+			name:           "json output delimited by NUL",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			outputFormat:   outputFormatJSON,
+			nulDelimited:   true,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				fzfArgs:          fzfArgsFunc("kubectl describe pods {1}", "kubectl get pods", false),
+				reloadAction:     "reload:kubectl get pods",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatJSON,
+					delimiter: "\x00",
+				},
+			},
+		},
+		{
+			name:           "watch mode for single resource",
+			resource:       kubernetesResourcePods,
+			namespace:      "default",
+			previewCommand: kubectlOutputFormatDescribe,
+			watchInterval:  2 * time.Second,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource:  kubernetesResourcePods,
+					namespace: "default",
+				},
+				fzfArgs: append(fzfArgsFunc("kubectl describe pods {1} -n=default", "kubectl get pods -n=default", false),
+					"--track",
+					"--id-nth", "1",
+				),
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				watchInterval:    2 * time.Second,
+				reloadAction:     "reload:kubectl get pods -n=default",
+				namespace:        "default",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+			},
+		},
+		{
+			name:           "watch mode for multiple resources across all namespaces",
+			resource:       kubernetesResourcePods + "," + kubernetesResourceService,
+			allNamespaces:  true,
+			previewCommand: kubectlOutputFormatDescribe,
+			watchInterval:  time.Second,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource:      kubernetesResourcePods + "," + kubernetesResourceService,
+					allNamespaces: true,
+				},
+				getOptions: map[string]string{
+					"--no-headers":     "true",
+					"--all-namespaces": "true",
+				},
+				fzfArgs: append(fzfArgsFunc("kubectl describe {2} -n={1}", "kubectl get pods,svc --all-namespaces=true --no-headers=true", true),
+					"--track",
+					"--id-nth", "1,2",
+				),
+				allNamespaces: true,
+				output: &output{
+					format:    outputFormatNamespaceName,
+					delimiter: "\n",
+				},
+				watchInterval: time.Second,
+				reloadAction:  "reload:kubectl get pods,svc --all-namespaces=true --no-headers=true",
+			},
+		},
+		{
+			name:           "selectors for multiple resources in the watch mode",
+			resource:       kubernetesResourcePods + "," + kubernetesResourceService,
+			namespace:      "default",
+			previewCommand: kubectlOutputFormatDescribe,
+			watchInterval:  time.Second,
+			options: map[string]string{
+				"--selector":       "app=payments",
+				"--field-selector": "metadata.namespace!=kube-system",
+				"--show-labels":    "true",
+			},
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource:  kubernetesResourcePods + "," + kubernetesResourceService,
+					namespace: "default",
+				},
+				getOptions: map[string]string{
+					"--selector":       "app=payments",
+					"--field-selector": "metadata.namespace!=kube-system",
+					"--show-labels":    "true",
+					"--no-headers":     "true",
+				},
+				fzfArgs: append(fzfArgsFunc("kubectl describe {1} -n=default", "kubectl get pods,svc -n=default '--field-selector=metadata.namespace!=kube-system' --no-headers=true --selector=app=payments --show-labels=true", true),
+					"--track",
+					"--id-nth", "1",
+				),
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				watchInterval:    time.Second,
+				reloadAction:     "reload:kubectl get pods,svc -n=default '--field-selector=metadata.namespace!=kube-system' --no-headers=true --selector=app=payments --show-labels=true",
+				namespace:        "default",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+			},
+		},
+		{
+			name:             "select containers of pods",
+			resource:         "po",
+			previewCommand:   kubectlOutputFormatDescribe,
+			selectContainers: true,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource: "po",
+				},
+				fzfArgs:          fzfArgsFunc("kubectl describe po {1}", "kubectl get po", false),
+				reloadAction:     "reload:kubectl get po",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				selectContainers: true,
+			},
+		},
+		{
+			name:             "select containers of other resources",
+			resource:         kubernetesResourcePods + "," + kubernetesResourceService,
+			previewCommand:   kubectlOutputFormatDescribe,
+			selectContainers: true,
+			wantErr:          errorInvalidArgumentSelectContainers,
+		},
+		{
+			name:             "select containers with the json output",
+			resource:         kubernetesResourcePods,
+			previewCommand:   kubectlOutputFormatDescribe,
+			outputFormat:     outputFormatJSON,
+			selectContainers: true,
+			wantErr:          errorInvalidArgumentSelectContainers,
+		},
+		{
+			name:           "navigate owned objects and owners",
+			resource:       "deployments",
+			namespace:      "default",
+			previewCommand: kubectlOutputFormatDescribe,
+			navigate:       true,
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource:  "deployments",
+					namespace: "default",
+				},
+				fzfArgs: fzfArgsFunc("kubectl describe deployments {1} -n=default", "kubectl get deployments -n=default", false,
+					"--header", "ctrl-o: owned objects, ctrl-b: back or owners",
+					"--expect", "ctrl-o,ctrl-b",
+				),
+				reloadAction:     "reload:kubectl get deployments -n=default",
+				namespace:        "default",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				navigation: &navigation{
+					backend: &kubectl{
+						resource:  "deployments",
+						namespace: "default",
+					},
+					previewFormat: kubectlOutputFormatDescribe,
+				},
+			},
+		},
+		{
+			name:             "navigate with selecting containers",
+			resource:         kubernetesResourcePods,
+			previewCommand:   kubectlOutputFormatDescribe,
+			selectContainers: true,
+			navigate:         true,
+			wantErr:          errorInvalidArgumentNavigation,
+		},
+		{
+			name:           "select one and exit zero",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			selectOptions: SelectOptions{
+				Query:     "api",
+				SelectOne: true,
+				ExitZero:  true,
+			},
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				fzfArgs:          fzfArgsFunc("kubectl describe pods {1}", "kubectl get pods", false, "--query", "api", "--select-1", "--exit-0"),
+				reloadAction:     "reload:kubectl get pods",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				selectOne: true,
+			},
+		},
+		{
+			name:           "filter",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			selectOptions: SelectOptions{
+				Query:     "api",
+				Filter:    true,
+				SelectOne: true,
+			},
+			want: &getCli{
+				config: resolved,
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				fzfArgs:          []string{"--filter", "api", "--header-lines", "1"},
+				reloadAction:     "reload:kubectl get pods",
+				namespaceKubectl: &kubectl{resource: "namespaces"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				filter:    true,
+				selectOne: true,
+			},
+		},
+		{
+			name:           "filter with the watch mode",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			selectOptions:  SelectOptions{Filter: true},
+			watchInterval:  time.Second,
+			wantErr:        errorInvalidArgumentFilter,
+		},
+		{
+			name:           "unknown finder",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			finder:         "peco",
+			wantErr:        fmt.Errorf("finder must be one of [auto, fzf, builtin]"),
+		},
+		{
+			name:           "negative watch interval",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			watchInterval:  -time.Second,
+			want:           nil,
+			wantErr:        errorInvalidArgumentWatchInterval,
+		},
+		{
+			name:           "invalid output format",
+			resource:       kubernetesResourcePods,
+			previewCommand: kubectlOutputFormatDescribe,
+			outputFormat:   "wide",
+			want:           nil,
+			wantErr:        errorInvalidArgumentOutputFormat,
+		},
 		{
 			name:           "invalid preview command",
 			resource:       kubernetesResourcePods,
@@ -130,20 +521,94 @@ func TestNewGetCli(t *testing.T) {
 				}
 			}
 			k := &kubectl{
-				resource:  tc.resource,
-				namespace: tc.namespace,
+				resource:      tc.resource,
+				namespace:     tc.namespace,
+				allNamespaces: tc.allNamespaces,
 			}
-			got, gotErr := NewGetCli(k, tc.previewCommand, tc.fzfQuery)
+			got, gotErr := NewGetCli(k, GetOptions{
+				PreviewFormat:    tc.previewCommand,
+				SelectOptions:    tc.selectOptions,
+				OutputFormat:     tc.outputFormat,
+				NulDelimited:     tc.nulDelimited,
+				WatchInterval:    tc.watchInterval,
+				KubectlOptions:   tc.options,
+				SelectContainers: tc.selectContainers,
+				Navigate:         tc.navigate,
+				Config:           config,
+				Finder:           tc.finder,
+			})
 			assert.Equal(t, tc.want, got)
 			assert.Equal(t, tc.wantErr, gotErr)
 		})
 	}
 }
 
+func TestNewGetCli_secrets(t *testing.T) {
+	backupExecutable := executable
+	defer func() {
+		executable = backupExecutable
+	}()
+	executable = func() (string, error) {
+		return "/usr/local/bin/kubectl-fzf", nil
+	}
+	// outputFormatRegexp matches output formats of kubectl, and JSON is piped to kubectl-fzf to decode secrets
+	outputFormatRegexp := regexp.MustCompile(`-o=(\S+)( \| /usr/local/bin/kubectl-fzf decode-secret)?`)
+
+	testCases := []struct {
+		name          string
+		resource      string
+		previewFormat string
+		config        *Config
+	}{
+		{
+			name:          "secret preview",
+			resource:      "secrets",
+			previewFormat: previewFormatSecret,
+			config:        &Config{},
+		},
+		{
+			name:          "secret preview which cannot be revealed",
+			resource:      "secrets",
+			previewFormat: previewFormatSecret,
+			config:        &Config{DisableSecretReveal: true},
+		},
+		{
+			name:          "describe preview of secrets which cannot be revealed",
+			resource:      "pods,secrets",
+			previewFormat: kubectlOutputFormatDescribe,
+			config:        &Config{DisableSecretReveal: true},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := NewGetCli(&kubectl{resource: tc.resource, allNamespaces: true}, GetOptions{
+				PreviewFormat: tc.previewFormat,
+				Config:        tc.config,
+			})
+			require.NoError(t, err)
+			// No commands print data of secrets, except for decoded values revealed by the key
+			for _, arg := range got.fzfArgs {
+				for _, matches := range outputFormatRegexp.FindAllStringSubmatch(arg, -1) {
+					assert.Equal(t, "json", matches[1], arg)
+					assert.NotEmpty(t, matches[2], arg)
+				}
+				if tc.config.DisableSecretReveal {
+					assert.NotContains(t, arg, "--reveal")
+				}
+			}
+		})
+	}
+}
+
 func TestGetCli_Run(t *testing.T) {
-	fzfOption := "--inline-info"
-	defaultRunCommand := func(ctx context.Context, commandLine string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
-		assert.Contains(t, commandLine, fmt.Sprintf("| fzf %s", fzfOption))
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	fzfArgs := []string{"--inline-info"}
+	defaultRunCommand := func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
+		assert.Equal(t, fzfArgs, args)
 		return bytes.NewBufferString("pod 2/2 Running 2d").Bytes(), nil
 	}
 	defaultWantErr := errors.New("want error")
@@ -151,7 +616,7 @@ func TestGetCli_Run(t *testing.T) {
 
 	testCases := []struct {
 		name                string
-		runCommandWithFzf   func(ctx context.Context, commandLine string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error)
+		runCommandWithFzf   func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error)
 		sut                 getCli
 		kubectlGetErr       error
 		kubectlGetDetailErr error
@@ -165,22 +630,47 @@ func TestGetCli_Run(t *testing.T) {
 				kubectl: &kubectl{
 					resource: kubernetesResourcePods,
 				},
-				fzfOption: fzfOption,
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
 			runCommandWithFzf: defaultRunCommand,
 			wantErr:           nil,
 			wantIO:            "pod\n",
 			wantIOErr:         "",
 		},
+		{
+			name: "key of --expect from the fzf option is not an object",
+			sut: getCli{
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				fzfArgs: []string{"--expect", "alt-x"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+			},
+			runCommandWithFzf: func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
+				return []byte("alt-x\npod 2/2 Running 2d\n"), nil
+			},
+			wantIO: "pod\n",
+		},
 		{
 			name: "command with fzf error",
 			sut: getCli{
 				kubectl: &kubectl{
 					resource: kubernetesResourcePods,
 				},
-				fzfOption: fzfOption,
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
-			runCommandWithFzf: func(ctx context.Context, commandLine string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
+			runCommandWithFzf: func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
 				return nil, defaultWantErr
 			},
 			wantErr:   defaultWantErr,
@@ -194,7 +684,11 @@ func TestGetCli_Run(t *testing.T) {
 					resource:  kubernetesResourcePods,
 					namespace: "invalid",
 				},
-				fzfOption: fzfOption,
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
 			runCommandWithFzf: defaultRunCommand,
 			kubectlGetErr:     &exitErr,
@@ -208,22 +702,83 @@ func TestGetCli_Run(t *testing.T) {
 				kubectl: &kubectl{
 					resource: kubernetesResourcePods,
 				},
-				fzfOption: fzfOption,
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
-			runCommandWithFzf: func(ctx context.Context, commandLine string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
+			runCommandWithFzf: func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
 				return nil, &exitErr
 			},
 			wantErr:   &exitErr,
 			wantIO:    "",
 			wantIOErr: "",
 		},
+		{
+			name: "canceled",
+			sut: getCli{
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+			},
+			runCommandWithFzf: func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
+				return nil, newExitError(t, 130)
+			},
+			wantErr: ErrCanceled,
+		},
+		{
+			name: "no objects match the query",
+			sut: getCli{
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+			},
+			runCommandWithFzf: func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
+				return nil, errorFinderNoMatch
+			},
+			wantErr: ErrNoMatch,
+		},
+		{
+			name: "multiple objects match the query on the filter with select one",
+			sut: getCli{
+				kubectl: &kubectl{
+					resource: kubernetesResourcePods,
+				},
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				filter:    true,
+				selectOne: true,
+			},
+			runCommandWithFzf: func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) (i []byte, e error) {
+				return []byte("pod1 2/2 Running 2d\npod2 2/2 Running 2d\n"), nil
+			},
+			wantErr: errorMultipleMatches,
+		},
 		{
 			name: "kubectl get command error",
 			sut: getCli{
 				kubectl: &kubectl{
 					resource: kubernetesResourcePods,
 				},
-				fzfOption: fzfOption,
+				fzfArgs: fzfArgs,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
 			},
 			runCommandWithFzf: defaultRunCommand,
 			kubectlGetErr:     defaultWantErr,
@@ -258,3 +813,331 @@ func TestGetCli_Run(t *testing.T) {
 		})
 	}
 }
+
+func TestGetCli_listObjects(t *testing.T) {
+	exitErr := errors.New("exit status 1")
+	namespaceNotFoundErr := &KubectlError{
+		Args:   []string{"get", "namespaces", "dev", "-o=name"},
+		Stderr: `Error from server (NotFound): namespaces "dev" not found`,
+		Err:    exitErr,
+	}
+
+	testCases := []struct {
+		name             string
+		namespace        string
+		allNamespaces    bool
+		kubectlOut       string
+		kubectlErr       error
+		namespaceErr     error
+		wantNamespaceRun bool
+		want             string
+		wantErr          error
+		wantMessage      string
+	}{
+		{
+			name:       "objects",
+			kubectlOut: "NAME READY STATUS AGE\npod1 1/1 Running 2d\n",
+			want:       "NAME READY STATUS AGE\npod1 1/1 Running 2d\n",
+		},
+		{
+			name:       "only one object without headers",
+			kubectlOut: "pod/pod1 1/1 Running 2d\n",
+			want:       "pod/pod1 1/1 Running 2d\n",
+		},
+		{
+			name:             "no objects",
+			namespace:        "default",
+			kubectlOut:       "No resources found in default namespace.\n",
+			wantNamespaceRun: true,
+			wantErr:          ErrNoObjects,
+			wantMessage:      "No resources found in default namespace.",
+		},
+		{
+			name:          "no objects across all namespaces",
+			allNamespaces: true,
+			kubectlOut:    "No resources found\n",
+			wantErr:       ErrNoObjects,
+			wantMessage:   "No resources found",
+		},
+		{
+			name:        "no objects by client-go",
+			kubectlOut:  "",
+			wantErr:     ErrNoObjects,
+			wantMessage: "No resources found",
+		},
+		{
+			name:             "namespace of the argument does not exist",
+			namespace:        "dev",
+			kubectlOut:       "No resources found in dev namespace.\n",
+			namespaceErr:     namespaceNotFoundErr,
+			wantNamespaceRun: true,
+			wantErr:          ErrNamespaceNotFound,
+			wantMessage:      `namespace "dev" does not exist`,
+		},
+		{
+			name:             "namespace of the context does not exist",
+			kubectlOut:       "No resources found in dev namespace.\n",
+			namespaceErr:     apierrors.NewNotFound(schema.GroupResource{Resource: "namespaces"}, "dev"),
+			wantNamespaceRun: true,
+			wantErr:          ErrNamespaceNotFound,
+			wantMessage:      `namespace "dev" does not exist`,
+		},
+		{
+			name:             "namespace cannot be checked",
+			namespace:        "dev",
+			kubectlOut:       "No resources found in dev namespace.\n",
+			namespaceErr:     &KubectlError{Stderr: `Error from server (Forbidden): namespaces "dev" is forbidden`, Err: exitErr},
+			wantNamespaceRun: true,
+			wantErr:          ErrNoObjects,
+			wantMessage:      "No resources found in dev namespace.",
+		},
+		{
+			name: "unknown resource type",
+			kubectlErr: &KubectlError{
+				Stderr: `error: the server doesn't have a resource type "pod2"`,
+				Err:    exitErr,
+			},
+			wantErr:     ErrUnknownResource,
+			wantMessage: `the server doesn't have a resource type "pod2". Run kubectl api-resources to see resource types`,
+		},
+		{
+			name: "forbidden by RBAC",
+			kubectlErr: &KubectlError{
+				Stderr: `Error from server (Forbidden): pods is forbidden: User "dev" cannot list resource "pods" in API group "" in the namespace "default"`,
+				Err:    exitErr,
+			},
+			wantErr:     ErrForbidden,
+			wantMessage: `permission denied by RBAC: pods is forbidden: User "dev" cannot list resource "pods" in API group "" in the namespace "default"`,
+		},
+		{
+			name:        "forbidden by RBAC on client-go",
+			kubectlErr:  fmt.Errorf("failed to list pods: %w", apierrors.NewForbidden(schema.GroupResource{Resource: "pods"}, "", errors.New("RBAC"))),
+			wantErr:     ErrForbidden,
+			wantMessage: `permission denied by RBAC: failed to list pods: pods is forbidden: RBAC`,
+		},
+		{
+			name:        "other error",
+			kubectlErr:  exitErr,
+			wantErr:     exitErr,
+			wantMessage: "exit status 1",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), "get", gomock.Any(), gomock.Any()).
+				Return([]byte(tc.kubectlOut), tc.kubectlErr).
+				Times(1)
+			sut := getCli{
+				kubectl:       mockKubectl,
+				namespace:     tc.namespace,
+				allNamespaces: tc.allNamespaces,
+			}
+			if !tc.allNamespaces {
+				mockNamespaceKubectl := NewMockKubectl(mockCtrl)
+				times := 0
+				if tc.wantNamespaceRun {
+					times = 1
+				}
+				mockNamespaceKubectl.EXPECT().
+					run(gomock.Any(), "get", gomock.Any(), map[string]string{"-o": "name"}).
+					Return([]byte("namespace/default"), tc.namespaceErr).
+					Times(times)
+				sut.namespaceKubectl = mockNamespaceKubectl
+			}
+
+			got, gotErr := sut.listObjects(context.Background())
+			assert.Equal(t, tc.want, string(got))
+			if tc.wantErr == nil {
+				assert.NoError(t, gotErr)
+				return
+			}
+			assert.True(t, errors.Is(gotErr, tc.wantErr), gotErr)
+			assert.EqualError(t, gotErr, tc.wantMessage)
+		})
+	}
+}
+
+func TestGetCli_Run_fzfInput(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	var longList strings.Builder
+	longList.WriteString("NAME READY STATUS AGE\n")
+	for i := 0; i < 100000; i++ {
+		longList.WriteString(fmt.Sprintf("pod-%d 1/1 Running 2d\n", i))
+	}
+
+	testCases := []struct {
+		name          string
+		allNamespaces bool
+		kubectlOut    string
+		fzfOut        string
+		wantIO        string
+	}{
+		{
+			name:          "all namespaces",
+			allNamespaces: true,
+			kubectlOut:    "NAMESPACE NAME READY STATUS AGE\ndefault pod1 1/1 Running 2d\nkube-system pod2 1/1 Running 2d\n",
+			fzfOut:        "default pod1 1/1 Running 2d\nkube-system pod2 1/1 Running 2d\n",
+			wantIO:        "default/pod1\nkube-system/pod2\n",
+		},
+		{
+			name:       "rows with single quotes",
+			kubectlOut: "NAME ANNOTATION\npod-'1' it's\npod2 'quoted'\n",
+			fzfOut:     "pod-'1' it's\npod2 'quoted'\n",
+			wantIO:     "pod-'1'\npod2\n",
+		},
+		{
+			name:       "rows with command substitutions",
+			kubectlOut: "NAME LABEL\npod1 $(touch /tmp/kubectl-fzf)\npod2 `id`\n",
+			fzfOut:     "pod1 $(touch /tmp/kubectl-fzf)\n",
+			wantIO:     "pod1\n",
+		},
+		{
+			name:       "very long list",
+			kubectlOut: longList.String(),
+			fzfOut:     "pod-99999 1/1 Running 2d\n",
+			wantIO:     "pod-99999\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), "get", gomock.Any(), gomock.Any()).
+				Return([]byte(tc.kubectlOut), nil).
+				Times(1)
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				got, err := io.ReadAll(ioIn)
+				require.NoError(t, err)
+				assert.Equal(t, tc.kubectlOut, string(got))
+				return []byte(tc.fzfOut), nil
+			}
+
+			output, err := newOutput("", tc.allNamespaces, false)
+			require.NoError(t, err)
+			sut := getCli{
+				kubectl:       mockKubectl,
+				fzfArgs:       []string{"--multi"},
+				allNamespaces: tc.allNamespaces,
+				output:        output,
+			}
+			var gotIOOut bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, io.Discard)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.wantIO, gotIOOut.String())
+		})
+	}
+}
+
+// chanWriter sends each written string to the channel
+type chanWriter chan string
+
+func (w chanWriter) Write(p []byte) (int, error) {
+	w <- string(p)
+	return len(p), nil
+}
+
+func TestGetCli_Run_watch(t *testing.T) {
+	backupRunCommandWithFzf := runCommandWithFzf
+	backupPostFzfAction := postFzfAction
+	defer func() {
+		runCommandWithFzf = backupRunCommandWithFzf
+		postFzfAction = backupPostFzfAction
+	}()
+
+	startBindingRegexp := regexp.MustCompile(`^start:execute-silent\(echo \$FZF_PORT > (\S+)\)$`)
+	testCases := []struct {
+		name    string
+		postErr error
+		wantErr string
+	}{
+		{
+			name: "reloaded",
+		},
+		{
+			name:    "the watch mode is stopped by an error",
+			postErr: errors.New("fzf returns the status 403 Forbidden for the action reload:kubectl get pods"),
+			wantErr: "the watch mode is stopped: fzf returns the status 403 Forbidden for the action reload:kubectl get pods\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), "get", gomock.Any(), gomock.Any()).
+				Return([]byte("NAME READY STATUS AGE\npod1 0/1 Pending 1s\n"), nil).
+				Times(1)
+
+			type postedAction struct {
+				address string
+				apiKey  string
+				action  string
+			}
+			posted := make(chan postedAction, 1)
+			postFzfAction = func(ctx context.Context, address string, apiKey string, action string) error {
+				select {
+				case posted <- postedAction{address: address, apiKey: apiKey, action: action}:
+				default:
+				}
+				return tc.postErr
+			}
+			apiKey := os.Getenv(envNameFzfAPIKey)
+			gotIOErr := make(chanWriter, 1)
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				require.Equal(t, []string{"--listen-unsafe=localhost:0", "--bind"}, args[len(args)-3:len(args)-1])
+				matches := startBindingRegexp.FindStringSubmatch(args[len(args)-1])
+				require.Len(t, matches, 2)
+				// fzf writes the port chosen by itself on the start event
+				require.NoError(t, os.WriteFile(matches[1], []byte("10000\n"), 0600))
+
+				fzfAPIKey := os.Getenv(envNameFzfAPIKey)
+				assert.NotEmpty(t, fzfAPIKey)
+				assert.NotEqual(t, apiKey, fzfAPIKey)
+				select {
+				case got := <-posted:
+					assert.Equal(t, postedAction{address: "localhost:10000", apiKey: fzfAPIKey, action: "reload:kubectl get pods"}, got)
+				case <-time.After(time.Second):
+					t.Error("fzf is not reloaded")
+				}
+				if tc.wantErr != "" {
+					select {
+					case got := <-gotIOErr:
+						assert.Equal(t, tc.wantErr, got)
+					case <-time.After(time.Second):
+						t.Error("the error is not written")
+					}
+				}
+				return []byte("pod1 1/1 Running 2s\n"), nil
+			}
+
+			sut := getCli{
+				kubectl: mockKubectl,
+				fzfArgs: []string{"--multi"},
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+				watchInterval: time.Millisecond,
+				reloadAction:  "reload:kubectl get pods",
+			}
+			var gotIOOut bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, gotIOErr)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, "pod1\n", gotIOOut.String())
+			assert.Equal(t, []string{"--multi"}, sut.fzfArgs)
+			assert.Empty(t, gotIOErr)
+			assert.Equal(t, apiKey, os.Getenv(envNameFzfAPIKey))
+		})
+	}
+}
@@ -7,6 +7,7 @@ package command
 import (
 	context "context"
 	gomock "github.com/golang/mock/gomock"
+	io "io"
 	reflect "reflect"
 )
 
@@ -61,3 +62,17 @@ func (mr *MockKubectlMockRecorder) run(arg0, arg1, arg2, arg3 interface{}) *gomo
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "run", reflect.TypeOf((*MockKubectl)(nil).run), arg0, arg1, arg2, arg3)
 }
+
+// runWithIO mocks base method
+func (m *MockKubectl) runWithIO(arg0 context.Context, arg1, arg2 string, arg3 []string, arg4 map[string]string, arg5 []string, arg6 io.Reader, arg7, arg8 io.Writer) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "runWithIO", arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// runWithIO indicates an expected call of runWithIO
+func (mr *MockKubectlMockRecorder) runWithIO(arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "runWithIO", reflect.TypeOf((*MockKubectl)(nil).runWithIO), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7, arg8)
+}
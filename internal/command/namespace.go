@@ -0,0 +1,53 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+)
+
+var errorMultipleNamespacesToSwitch = errors.New("only one namespace can be selected to switch")
+
+type namespaceCli struct {
+	getCli          *getCli
+	kubectl         *kubectl
+	switchNamespace bool
+}
+
+// NewNamespaceCli returns the cli to select namespaces on fzf.
+// If switchNamespace is true, the namespace of the context is switched to the selected one.
+func NewNamespaceCli(backend kubectlBackend, cli *getCli, switchNamespace bool) (*namespaceCli, error) {
+	return &namespaceCli{
+		getCli:          cli,
+		kubectl:         backend.resourceKubectl(),
+		switchNamespace: switchNamespace,
+	}, nil
+}
+
+func (c namespaceCli) Run(ctx context.Context, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	if !c.switchNamespace {
+		return c.getCli.Run(ctx, ioIn, ioOut, ioErr)
+	}
+
+	objects, err := c.getCli.selectObjects(ctx, ioErr)
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return nil
+	}
+	if len(objects) > 1 {
+		return errorMultipleNamespacesToSwitch
+	}
+
+	options := map[string]string{
+		"--namespace": objects[0].name,
+	}
+	var names []string
+	if c.kubectl.kubeContext == "" {
+		options["--current"] = "true"
+	} else {
+		names = []string{c.kubectl.kubeContext}
+	}
+	return c.kubectl.runWithIO(ctx, "config", "set-context", names, options, nil, ioIn, ioOut, ioErr)
+}
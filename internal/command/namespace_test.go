@@ -0,0 +1,99 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceCli_Run(t *testing.T) {
+	backupRunKubectlWithIO := runKubectlWithIO
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runKubectlWithIO = backupRunKubectlWithIO
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	testCases := []struct {
+		name              string
+		kubeContext       string
+		switchNamespace   bool
+		fzfOut            string
+		wantKubectlWithIO []string
+		wantIO            string
+		wantErr           error
+	}{
+		{
+			name:   "output namespaces",
+			fzfOut: "default Active 2d\nkube-system Active 2d\n",
+			wantIO: "default\nkube-system\n",
+		},
+		{
+			name:              "switch the namespace of the current context",
+			switchNamespace:   true,
+			fzfOut:            "kube-system Active 2d\n",
+			wantKubectlWithIO: []string{"config", "set-context", "--current=true", "--namespace=kube-system"},
+		},
+		{
+			name:              "switch the namespace of the context",
+			kubeContext:       "prod",
+			switchNamespace:   true,
+			fzfOut:            "kube-system Active 2d\n",
+			wantKubectlWithIO: []string{"config", "set-context", "prod", "--context=prod", "--namespace=kube-system"},
+		},
+		{
+			name:            "multiple namespaces to switch",
+			switchNamespace: true,
+			fzfOut:          "default Active 2d\nkube-system Active 2d\n",
+			wantErr:         errorMultipleNamespacesToSwitch,
+		},
+		{
+			name:            "fzf is canceled",
+			switchNamespace: true,
+			fzfOut:          "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			mockKubectl.EXPECT().
+				run(gomock.Any(), "get", gomock.Any(), gomock.Any()).
+				Return([]byte("NAME STATUS AGE\ndefault Active 2d\nkube-system Active 2d\n"), nil).
+				Times(1)
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				return []byte(tc.fzfOut), nil
+			}
+			var gotKubectlWithIO []string
+			runKubectlWithIO = func(ctx context.Context, args []string, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+				gotKubectlWithIO = args
+				return nil
+			}
+
+			k := &kubectl{
+				resource:    "namespaces",
+				kubeContext: tc.kubeContext,
+			}
+			sut, err := NewNamespaceCli(k, &getCli{
+				kubectl: mockKubectl,
+				output: &output{
+					format:    outputFormatName,
+					delimiter: "\n",
+				},
+			}, tc.switchNamespace)
+			assert.NoError(t, err)
+
+			var gotIOOut bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, io.Discard)
+			assert.Equal(t, tc.wantErr, gotErr)
+			assert.Equal(t, tc.wantIO, gotIOOut.String())
+			assert.Equal(t, tc.wantKubectlWithIO, gotKubectlWithIO)
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+const (
+	// navigationKeyOwned lists objects owned by the object on the cursor
+	navigationKeyOwned = "ctrl-o"
+	// navigationKeyOwners goes back to the previous list, or lists owners of the object on the cursor
+	navigationKeyOwners = "ctrl-b"
+
+	navigationHeader = navigationKeyOwned + ": owned objects, " + navigationKeyOwners + ": back or owners"
+)
+
+var (
+	errorInvalidArgumentNavigation = errors.New("navigation cannot be used to select containers")
+
+	// ownedResources are resources of objects owned by an object of each kind
+	ownedResources = map[string][]string{
+		"Deployment":  {"replicasets.apps"},
+		"ReplicaSet":  {"pods"},
+		"StatefulSet": {"pods"},
+		"DaemonSet":   {"pods"},
+		"Job":         {"pods"},
+		"CronJob":     {"jobs.batch"},
+	}
+)
+
+type navigation struct {
+	backend       kubectlBackend
+	previewFormat string
+}
+
+// navigationObject is a subset of an object or a list returned by "kubectl get -o json" for the navigation
+type navigationObject struct {
+	Kind     string `json:"kind"`
+	Metadata struct {
+		Namespace       string `json:"namespace"`
+		Name            string `json:"name"`
+		UID             string `json:"uid"`
+		OwnerReferences []struct {
+			APIVersion string `json:"apiVersion"`
+			Kind       string `json:"kind"`
+			Name       string `json:"name"`
+			UID        string `json:"uid"`
+		} `json:"ownerReferences"`
+	} `json:"metadata"`
+	Spec struct {
+		// Selector is a map for a service, and a label selector for workloads
+		Selector json.RawMessage `json:"selector"`
+	} `json:"spec"`
+	Items []navigationObject `json:"items"`
+}
+
+// navigationRef is an object listed by the navigation
+type navigationRef struct {
+	resource string
+	name     string
+}
+
+// splitFzfExpectedKey returns the key printed by fzf --expect and selected rows
+func splitFzfExpectedKey(out string) (string, string) {
+	i := strings.Index(out, "\n")
+	if i < 0 {
+		return strings.TrimSpace(out), ""
+	}
+	return strings.TrimSpace(out[:i]), out[i+1:]
+}
+
+// navigate selects objects on fzf, while objects owned by the object on the cursor or its owners are listed by keys.
+// It returns the cli listing the selected objects and the key to select them.
+func (c getCli) navigate(ctx context.Context, ioErr io.Writer) (*getCli, []resourceObject, string, error) {
+	cli := &c
+	var parents []*getCli
+	for {
+		objects, key, err := cli.selectObjectsWithKey(ctx, ioErr)
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if key != navigationKeyOwned && key != navigationKeyOwners {
+			return cli, objects, key, nil
+		}
+		if key == navigationKeyOwners && len(parents) > 0 {
+			cli = parents[len(parents)-1]
+			parents = parents[:len(parents)-1]
+			continue
+		}
+		if len(objects) == 0 {
+			continue
+		}
+
+		var next *getCli
+		message := fmt.Sprintf("%s has no owned objects", objects[0])
+		if key == navigationKeyOwned {
+			next, err = cli.ownedLevel(ctx, objects[0])
+		} else {
+			next, err = cli.ownersLevel(ctx, objects[0])
+			message = fmt.Sprintf("%s has no owners", objects[0])
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if next == nil {
+			cli = cli.withHeader(message)
+			continue
+		}
+		if key == navigationKeyOwned {
+			parents = append(parents, cli)
+		}
+		cli = next
+	}
+}
+
+// withHeader returns the cli showing the message on the header of fzf
+func (c getCli) withHeader(message string) *getCli {
+	fzfArgs := make([]string, 0, len(c.fzfArgs)+2)
+	for i := 0; i < len(c.fzfArgs); i++ {
+		// Remove the previous message
+		if c.fzfArgs[i] == "--header" && i+1 < len(c.fzfArgs) && c.fzfArgs[i+1] != navigationHeader {
+			i++
+			continue
+		}
+		fzfArgs = append(fzfArgs, c.fzfArgs[i])
+	}
+	c.fzfArgs = append(fzfArgs, "--header", message)
+	return &c
+}
+
+// ownedLevel returns the cli listing objects owned by the object, or nil if there are no such objects
+func (c getCli) ownedLevel(ctx context.Context, object resourceObject) (*getCli, error) {
+	parent, err := c.getNavigationObject(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	namespace := parent.Metadata.Namespace
+
+	var refs []navigationRef
+	for _, resource := range ownedResources[parent.Kind] {
+		items, err := c.listNavigationObjects(ctx, resource, namespace, nil)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			for _, owner := range item.Metadata.OwnerReferences {
+				if owner.UID == parent.Metadata.UID {
+					refs = append(refs, navigationRef{
+						resource: resource,
+						name:     item.Metadata.Name,
+					})
+					break
+				}
+			}
+		}
+	}
+	if parent.Kind == "Service" {
+		serviceRefs, err := c.getServiceRefs(ctx, parent)
+		if err != nil {
+			return nil, err
+		}
+		refs = append(refs, serviceRefs...)
+	}
+	return c.newNavigationLevel(namespace, refs)
+}
+
+// getServiceRefs returns the endpoints and pods selected by the service
+func (c getCli) getServiceRefs(ctx context.Context, service navigationObject) ([]navigationRef, error) {
+	namespace := service.Metadata.Namespace
+	var refs []navigationRef
+	endpoints, err := c.listNavigationObjects(ctx, "endpoints", namespace, map[string]string{
+		"--field-selector": "metadata.name=" + service.Metadata.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range endpoints {
+		refs = append(refs, navigationRef{
+			resource: "endpoints",
+			name:     item.Metadata.Name,
+		})
+	}
+
+	var selector map[string]string
+	if len(service.Spec.Selector) > 0 {
+		if err := json.Unmarshal(service.Spec.Selector, &selector); err != nil {
+			return nil, fmt.Errorf("failed to parse the selector of the service %s: %w", service.Metadata.Name, err)
+		}
+	}
+	if len(selector) == 0 {
+		return refs, nil
+	}
+	labels := make([]string, 0, len(selector))
+	for k, v := range selector {
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	pods, err := c.listNavigationObjects(ctx, "pods", namespace, map[string]string{
+		"--selector": strings.Join(labels, ","),
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range pods {
+		refs = append(refs, navigationRef{
+			resource: "pods",
+			name:     item.Metadata.Name,
+		})
+	}
+	return refs, nil
+}
+
+// ownersLevel returns the cli listing owners of the object, or nil if there are no owners
+func (c getCli) ownersLevel(ctx context.Context, object resourceObject) (*getCli, error) {
+	child, err := c.getNavigationObject(ctx, object)
+	if err != nil {
+		return nil, err
+	}
+	var refs []navigationRef
+	for _, owner := range child.Metadata.OwnerReferences {
+		resource := strings.ToLower(owner.Kind)
+		if i := strings.Index(owner.APIVersion, "/"); i >= 0 {
+			resource = resource + "." + owner.APIVersion[:i]
+		}
+		refs = append(refs, navigationRef{
+			resource: resource,
+			name:     owner.Name,
+		})
+	}
+	return c.newNavigationLevel(child.Metadata.Namespace, refs)
+}
+
+func (c getCli) getNavigationObject(ctx context.Context, object resourceObject) (navigationObject, error) {
+	options := map[string]string{
+		"-o": "json",
+	}
+	if object.namespace != "" {
+		options["-n"] = object.namespace
+	}
+	out, err := c.kubectl.run(ctx, "get", []string{object.name}, options)
+	if err != nil {
+		return navigationObject{}, err
+	}
+	var result navigationObject
+	if err := json.Unmarshal(out, &result); err != nil {
+		return navigationObject{}, fmt.Errorf("failed to parse the output of kubectl: %w", err)
+	}
+	return result, nil
+}
+
+func (c getCli) listNavigationObjects(ctx context.Context, resource string, namespace string, options map[string]string) ([]navigationObject, error) {
+	k := &kubectl{
+		resource:    resource,
+		namespace:   namespace,
+		kubeContext: c.navigation.backend.resourceKubectl().kubeContext,
+	}
+	listOptions := map[string]string{
+		"-o": "json",
+	}
+	for k, v := range options {
+		listOptions[k] = v
+	}
+	out, err := c.navigation.backend.withKubectl(k).run(ctx, "get", nil, listOptions)
+	if err != nil {
+		return nil, err
+	}
+	var list navigationObject
+	if err := json.Unmarshal(out, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse the output of kubectl: %w", err)
+	}
+	return list.Items, nil
+}
+
+// newNavigationLevel returns the cli listing the objects, or nil if there are no objects
+func (c getCli) newNavigationLevel(namespace string, refs []navigationRef) (*getCli, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	var resources []string
+	for _, ref := range refs {
+		found := false
+		for _, resource := range resources {
+			if resource == ref.resource {
+				found = true
+				break
+			}
+		}
+		if !found {
+			resources = append(resources, ref.resource)
+		}
+	}
+	names := make([]string, len(refs))
+	for i, ref := range refs {
+		names[i] = ref.name
+		if len(resources) > 1 {
+			// names are like pod/name for multiple resources
+			names[i] = ref.resource + "/" + ref.name
+		}
+	}
+
+	k := &kubectl{
+		resource:    strings.Join(resources, ","),
+		namespace:   namespace,
+		kubeContext: c.navigation.backend.resourceKubectl().kubeContext,
+	}
+	previewFormat := c.navigation.previewFormat
+	if _, err := getPreviewCommand(k, previewFormat, c.config); err != nil {
+		// Formats like logs are not supported for some kinds
+		previewFormat = kubectlOutputFormatDescribe
+	}
+	cli, err := newGetCli(c.navigation.backend.withKubectl(k), names, GetOptions{
+		PreviewFormat: previewFormat,
+		OutputFormat:  c.output.format,
+		Navigate:      true,
+	}, c.config)
+	if err != nil {
+		return nil, err
+	}
+	cli.output = c.output
+	cli.finder = c.finder
+	return cli, nil
+}
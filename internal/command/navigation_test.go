@@ -0,0 +1,181 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitFzfExpectedKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		out      string
+		wantKey  string
+		wantRows string
+	}{
+		{
+			name:     "enter",
+			out:      "\npod1 1/1 Running 2d\n",
+			wantKey:  "",
+			wantRows: "pod1 1/1 Running 2d\n",
+		},
+		{
+			name:     "key",
+			out:      "ctrl-o\npod1 1/1 Running 2d\n",
+			wantKey:  "ctrl-o",
+			wantRows: "pod1 1/1 Running 2d\n",
+		},
+		{
+			name:     "no rows",
+			out:      "ctrl-b",
+			wantKey:  "ctrl-b",
+			wantRows: "",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gotKey, gotRows := splitFzfExpectedKey(tc.out)
+			assert.Equal(t, tc.wantKey, gotKey)
+			assert.Equal(t, tc.wantRows, gotRows)
+		})
+	}
+}
+
+func TestGetCli_Run_navigate(t *testing.T) {
+	backupRunKubectl := runKubectl
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runKubectl = backupRunKubectl
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	kubectlOutputs := map[string]string{
+		"get deployments -n=default":                                           "NAME READY\ndeploy1 1/1\n",
+		"get deployments deploy1 -n=default -o=json":                           `{"kind": "Deployment", "metadata": {"name": "deploy1", "namespace": "default", "uid": "deploy1-uid"}, "spec": {"selector": {"matchLabels": {"app": "app1"}}}}`,
+		"get replicasets.apps -n=default -o=json":                              `{"kind": "List", "items": [{"metadata": {"name": "rs1", "ownerReferences": [{"kind": "Deployment", "name": "deploy1", "uid": "deploy1-uid"}]}}, {"metadata": {"name": "rs2", "ownerReferences": [{"kind": "Deployment", "name": "deploy2", "uid": "deploy2-uid"}]}}]}`,
+		"get replicasets.apps -n=default":                                      "NAME DESIRED\nrs1 1\n",
+		"get replicasets.apps rs1 -n=default":                                  "NAME DESIRED\nrs1 1\n",
+		"get replicasets.apps rs1 -n=default -o=json":                          `{"kind": "ReplicaSet", "metadata": {"name": "rs1", "namespace": "default", "uid": "rs1-uid", "ownerReferences": [{"apiVersion": "apps/v1", "kind": "Deployment", "name": "deploy1", "uid": "deploy1-uid"}]}}`,
+		"get pods -n=default -o=json":                                          `{"kind": "List", "items": [{"metadata": {"name": "pod1", "ownerReferences": [{"kind": "ReplicaSet", "name": "rs1", "uid": "rs1-uid"}]}}]}`,
+		"get pods pod1 -n=default":                                             "NAME READY\npod1 1/1\n",
+		"get pods pod1 -n=default -o=json":                                     `{"kind": "Pod", "metadata": {"name": "pod1", "namespace": "default", "uid": "pod1-uid"}}`,
+		"get deployment.apps deploy1 -n=default":                               "NAME READY\ndeploy1 1/1\n",
+		"get services -n=default":                                              "NAME TYPE\nsvc1 ClusterIP\n",
+		"get services svc1 -n=default -o=json":                                 `{"kind": "Service", "metadata": {"name": "svc1", "namespace": "default", "uid": "svc1-uid"}, "spec": {"selector": {"app": "app1", "tier": "web"}}}`,
+		"get endpoints -n=default --field-selector=metadata.name=svc1 -o=json": `{"kind": "List", "items": [{"metadata": {"name": "svc1"}}]}`,
+		"get pods -n=default --selector=app=app1,tier=web -o=json":             `{"kind": "List", "items": [{"metadata": {"name": "pod1"}}]}`,
+		"get endpoints/svc1 pods/pod1 -n=default --no-headers=true":            "endpoints/svc1 10.0.0.1:80\npod/pod1 1/1\n",
+	}
+
+	testCases := []struct {
+		name     string
+		resource string
+		// fzfOuts are outputs of fzf in order, with the key of --expect on the 1st line
+		fzfOuts []string
+		// wantFzfHeaders are the last --header of fzf in order
+		wantFzfHeaders []string
+		want           string
+		wantErr        error
+	}{
+		{
+			name:     "select owned objects and go back",
+			resource: "deployments",
+			fzfOuts: []string{
+				"ctrl-o\ndeploy1 1/1\n",
+				"ctrl-o\nrs1 1\n",
+				"ctrl-b\npod1 1/1\n",
+				"\nrs1 1\n",
+			},
+			wantFzfHeaders: []string{navigationHeader, navigationHeader, navigationHeader, navigationHeader},
+			want:           "rs1\n",
+		},
+		{
+			name:     "select owners",
+			resource: "replicasets.apps",
+			fzfOuts: []string{
+				"ctrl-b\nrs1 1\n",
+				"\ndeploy1 1/1\n",
+			},
+			wantFzfHeaders: []string{navigationHeader, navigationHeader},
+			want:           "deploy1\n",
+		},
+		{
+			name:     "select objects selected by a service",
+			resource: "services",
+			fzfOuts: []string{
+				"ctrl-o\nsvc1 ClusterIP\n",
+				"\npod/pod1 1/1\n",
+			},
+			wantFzfHeaders: []string{navigationHeader, navigationHeader},
+			want:           "pod/pod1\n",
+		},
+		{
+			name:     "no owned objects",
+			resource: "deployments",
+			fzfOuts: []string{
+				"ctrl-o\ndeploy1 1/1\n",
+				"ctrl-o\nrs1 1\n",
+				"ctrl-o\npod1 1/1\n",
+				"\npod1 1/1\n",
+			},
+			wantFzfHeaders: []string{navigationHeader, navigationHeader, navigationHeader, "pod1 has no owned objects"},
+			want:           "pod1\n",
+		},
+		{
+			name:     "fzf is canceled",
+			resource: "deployments",
+			fzfOuts: []string{
+				"ctrl-o\ndeploy1 1/1\n",
+				"",
+			},
+			wantFzfHeaders: []string{navigationHeader, navigationHeader},
+			want:           "",
+			wantErr:        ErrCanceled,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
+				out, ok := kubectlOutputs[strings.Join(args, " ")]
+				require.True(t, ok, "unexpected kubectl %s", strings.Join(args, " "))
+				return []byte(out), nil
+			}
+			var gotFzfHeaders []string
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				require.Less(t, len(gotFzfHeaders), len(tc.fzfOuts), "fzf runs too many times")
+				for i := len(args) - 2; i >= 0; i-- {
+					if args[i] == "--header" {
+						gotFzfHeaders = append(gotFzfHeaders, args[i+1])
+						break
+					}
+				}
+				out := tc.fzfOuts[len(gotFzfHeaders)-1]
+				if out == "" {
+					return nil, newExitError(t, 130)
+				}
+				return []byte(out), nil
+			}
+
+			k := &kubectl{
+				resource:  tc.resource,
+				namespace: "default",
+			}
+			sut, err := NewGetCli(k, GetOptions{
+				PreviewFormat: kubectlOutputFormatDescribe,
+				Navigate:      true,
+			})
+			require.NoError(t, err)
+			var gotIOOut bytes.Buffer
+			gotErr := sut.Run(context.Background(), strings.NewReader(""), &gotIOOut, io.Discard)
+			assert.Equal(t, tc.wantErr, gotErr)
+			assert.Equal(t, tc.want, gotIOOut.String())
+			assert.Equal(t, tc.wantFzfHeaders, gotFzfHeaders, fmt.Sprintf("fzf outputs: %v", tc.fzfOuts))
+		})
+	}
+}
@@ -0,0 +1,207 @@
+package command
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	outputFormatName          = "name"
+	outputFormatKindName      = "kind/name"
+	outputFormatNamespaceName = "namespace/name"
+	outputFormatJSON          = "json"
+	outputFormatYaml          = "yaml"
+)
+
+var (
+	errorInvalidArgumentOutputFormat = errors.New("output format must be one of [name, kind/name, namespace/name, json, yaml, go-template=..., go-template-file=..., jsonpath=..., jsonpath-file=...]")
+
+	// outputFormatsForKubectl are passed to "kubectl get -o" as they are
+	outputFormatsForKubectl = []string{
+		"go-template=",
+		"go-template-file=",
+		"jsonpath=",
+		"jsonpath-file=",
+	}
+)
+
+type output struct {
+	format    string
+	delimiter string
+}
+
+// outputObject is an element of the json output
+type outputObject struct {
+	Kind       string `json:"kind"`
+	APIVersion string `json:"apiVersion"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// kubernetesObject is a subset of an object or a list returned by "kubectl get -o json"
+type kubernetesObject struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"metadata"`
+	Items []kubernetesObject `json:"items"`
+}
+
+// newOutput returns the output for the format.
+// If the format is empty, it outputs names, or namespace/name across all namespaces.
+func newOutput(format string, allNamespaces bool, nulDelimited bool) (*output, error) {
+	if format == "" {
+		format = outputFormatName
+		if allNamespaces {
+			format = outputFormatNamespaceName
+		}
+	}
+	if !isValidOutputFormat(format) {
+		return nil, errorInvalidArgumentOutputFormat
+	}
+	delimiter := "\n"
+	if nulDelimited {
+		delimiter = "\x00"
+	}
+	return &output{
+		format:    format,
+		delimiter: delimiter,
+	}, nil
+}
+
+func isValidOutputFormat(format string) bool {
+	switch format {
+	case outputFormatName, outputFormatKindName, outputFormatNamespaceName, outputFormatJSON, outputFormatYaml:
+		return true
+	}
+	for _, prefix := range outputFormatsForKubectl {
+		if strings.HasPrefix(format, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// formatObjects returns the output of objects, which ends with the delimiter.
+func (o output) formatObjects(ctx context.Context, k Kubectl, objects []resourceObject) ([]byte, error) {
+	var entries []string
+	switch o.format {
+	case outputFormatName:
+		for _, object := range objects {
+			entries = append(entries, object.withContainer(object.name))
+		}
+	case outputFormatNamespaceName, outputFormatKindName, outputFormatJSON:
+		if o.format == outputFormatNamespaceName && hasNamespaces(objects) {
+			for _, object := range objects {
+				entries = append(entries, object.withContainer(object.String()))
+			}
+			break
+		}
+
+		kubernetesObjects, err := getKubernetesObjects(ctx, k, objects)
+		if err != nil {
+			return nil, err
+		}
+		if o.format == outputFormatJSON {
+			outputObjects := make([]outputObject, len(kubernetesObjects))
+			for i, object := range kubernetesObjects {
+				outputObjects[i] = outputObject{
+					Kind:       object.Kind,
+					APIVersion: object.APIVersion,
+					Namespace:  object.Metadata.Namespace,
+					Name:       object.Metadata.Name,
+				}
+			}
+			out, err := json.MarshalIndent(outputObjects, "", "    ")
+			if err != nil {
+				return nil, fmt.Errorf("failed to marshal json: %w", err)
+			}
+			entries = append(entries, string(out))
+			break
+		}
+		for _, object := range kubernetesObjects {
+			if o.format == outputFormatKindName {
+				entries = append(entries, object.kindName())
+				continue
+			}
+			entries = append(entries, resourceObject{
+				namespace: object.Metadata.Namespace,
+				name:      object.Metadata.Name,
+			}.String())
+		}
+	default:
+		// yaml or formats supported by kubectl
+		var err error
+		entries, err = runGetForEachNamespace(ctx, k, objects, o.format)
+		if err != nil {
+			return nil, err
+		}
+		if o.format == outputFormatYaml {
+			entries = []string{strings.Join(entries, "\n---\n")}
+		}
+	}
+	return []byte(strings.Join(entries, o.delimiter) + o.delimiter), nil
+}
+
+func hasNamespaces(objects []resourceObject) bool {
+	for _, object := range objects {
+		if object.namespace == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// kindName returns the same string as "kubectl get -o name"
+func (o kubernetesObject) kindName() string {
+	kind := strings.ToLower(o.Kind)
+	if i := strings.Index(o.APIVersion, "/"); i >= 0 {
+		kind = kind + "." + o.APIVersion[:i]
+	}
+	return kind + "/" + o.Metadata.Name
+}
+
+func getKubernetesObjects(ctx context.Context, k Kubectl, objects []resourceObject) ([]kubernetesObject, error) {
+	outs, err := runGetForEachNamespace(ctx, k, objects, outputFormatJSON)
+	if err != nil {
+		return nil, err
+	}
+	var kubernetesObjects []kubernetesObject
+	for _, out := range outs {
+		var object kubernetesObject
+		if err := json.Unmarshal([]byte(out), &object); err != nil {
+			return nil, fmt.Errorf("failed to parse the output of kubectl: %w", err)
+		}
+		if object.Items != nil {
+			kubernetesObjects = append(kubernetesObjects, object.Items...)
+			continue
+		}
+		kubernetesObjects = append(kubernetesObjects, object)
+	}
+	return kubernetesObjects, nil
+}
+
+// runGetForEachNamespace runs "kubectl get" with the output format once for each namespace of objects
+func runGetForEachNamespace(ctx context.Context, k Kubectl, objects []resourceObject, format string) ([]string, error) {
+	namespaces, namesByNamespace := groupByNamespace(objects)
+	var outs []string
+	for _, namespace := range namespaces {
+		options := map[string]string{
+			"-o": format,
+		}
+		if namespace != "" {
+			options["-n"] = namespace
+		}
+		out, err := k.run(ctx, "get", namesByNamespace[namespace], options)
+		if err != nil {
+			return nil, err
+		}
+		outs = append(outs, strings.TrimSuffix(string(out), "\n"))
+	}
+	return outs, nil
+}
@@ -0,0 +1,283 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewOutput(t *testing.T) {
+	testCases := []struct {
+		name          string
+		format        string
+		allNamespaces bool
+		nulDelimited  bool
+		want          *output
+		wantErr       error
+	}{
+		{
+			name:   "default",
+			format: "",
+			want: &output{
+				format:    outputFormatName,
+				delimiter: "\n",
+			},
+		},
+		{
+			name:          "default across all namespaces",
+			format:        "",
+			allNamespaces: true,
+			want: &output{
+				format:    outputFormatNamespaceName,
+				delimiter: "\n",
+			},
+		},
+		{
+			name:          "name across all namespaces",
+			format:        outputFormatName,
+			allNamespaces: true,
+			want: &output{
+				format:    outputFormatName,
+				delimiter: "\n",
+			},
+		},
+		{
+			name:         "jsonpath delimited by NUL",
+			format:       "jsonpath={.metadata.uid}",
+			nulDelimited: true,
+			want: &output{
+				format:    "jsonpath={.metadata.uid}",
+				delimiter: "\x00",
+			},
+		},
+		{
+			name:    "unknown format",
+			format:  "wide",
+			wantErr: errorInvalidArgumentOutputFormat,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := newOutput(tc.format, tc.allNamespaces, tc.nulDelimited)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+func TestOutput_formatObjects(t *testing.T) {
+	podJSON := `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod1", "namespace": "default"}}`
+	listJSON := `{"apiVersion": "v1", "kind": "List", "items": [
+		{"apiVersion": "apps/v1", "kind": "Deployment", "metadata": {"name": "deploy1", "namespace": "default"}},
+		{"apiVersion": "v1", "kind": "Service", "metadata": {"name": "svc1", "namespace": "default"}}
+	]}`
+	defaultErr := errors.New("error")
+
+	type kubectlRun struct {
+		names   []string
+		options map[string]string
+		out     string
+		err     error
+	}
+	testCases := []struct {
+		name        string
+		output      output
+		objects     []resourceObject
+		kubectlRuns []kubectlRun
+		want        string
+		wantErr     error
+	}{
+		{
+			name: "name",
+			output: output{
+				format:    outputFormatName,
+				delimiter: "\n",
+			},
+			objects: []resourceObject{
+				{namespace: "default", name: "pod1"},
+				{namespace: "kube-system", name: "pod2"},
+			},
+			want: "pod1\npod2\n",
+		},
+		{
+			name: "namespace/name across all namespaces delimited by NUL",
+			output: output{
+				format:    outputFormatNamespaceName,
+				delimiter: "\x00",
+			},
+			objects: []resourceObject{
+				{namespace: "default", name: "pod1"},
+				{namespace: "kube-system", name: "pod2"},
+			},
+			want: "default/pod1\x00kube-system/pod2\x00",
+		},
+		{
+			name: "name and namespace/name with containers",
+			output: output{
+				format:    outputFormatNamespaceName,
+				delimiter: "\n",
+			},
+			objects: []resourceObject{
+				{namespace: "default", name: "pod1", container: "app"},
+				{namespace: "kube-system", name: "pod2", container: "coredns"},
+			},
+			want: "default/pod1 -c app\nkube-system/pod2 -c coredns\n",
+		},
+		{
+			name: "namespace/name in the current namespace",
+			output: output{
+				format:    outputFormatNamespaceName,
+				delimiter: "\n",
+			},
+			objects: []resourceObject{
+				{name: "pod1"},
+			},
+			kubectlRuns: []kubectlRun{
+				{
+					names:   []string{"pod1"},
+					options: map[string]string{"-o": "json"},
+					out:     podJSON,
+				},
+			},
+			want: "default/pod1\n",
+		},
+		{
+			name: "kind/name",
+			output: output{
+				format:    outputFormatKindName,
+				delimiter: "\n",
+			},
+			objects: []resourceObject{
+				{name: "deployment.apps/deploy1"},
+				{name: "service/svc1"},
+			},
+			kubectlRuns: []kubectlRun{
+				{
+					names:   []string{"deployment.apps/deploy1", "service/svc1"},
+					options: map[string]string{"-o": "json"},
+					out:     listJSON,
+				},
+			},
+			want: "deployment.apps/deploy1\nservice/svc1\n",
+		},
+		{
+			name: "json across all namespaces",
+			output: output{
+				format:    outputFormatJSON,
+				delimiter: "\n",
+			},
+			objects: []resourceObject{
+				{namespace: "default", name: "pod1"},
+				{namespace: "kube-system", name: "pod2"},
+			},
+			kubectlRuns: []kubectlRun{
+				{
+					names:   []string{"pod1"},
+					options: map[string]string{"-o": "json", "-n": "default"},
+					out:     podJSON,
+				},
+				{
+					names:   []string{"pod2"},
+					options: map[string]string{"-o": "json", "-n": "kube-system"},
+					out:     `{"apiVersion": "v1", "kind": "Pod", "metadata": {"name": "pod2", "namespace": "kube-system"}}`,
+				},
+			},
+			want: `[
+    {
+        "kind": "Pod",
+        "apiVersion": "v1",
+        "namespace": "default",
+        "name": "pod1"
+    },
+    {
+        "kind": "Pod",
+        "apiVersion": "v1",
+        "namespace": "kube-system",
+        "name": "pod2"
+    }
+]
+`,
+		},
+		{
+			name: "yaml across all namespaces",
+			output: output{
+				format:    outputFormatYaml,
+				delimiter: "\n",
+			},
+			objects: []resourceObject{
+				{namespace: "default", name: "pod1"},
+				{namespace: "kube-system", name: "pod2"},
+			},
+			kubectlRuns: []kubectlRun{
+				{
+					names:   []string{"pod1"},
+					options: map[string]string{"-o": "yaml", "-n": "default"},
+					out:     "kind: Pod\nmetadata:\n  name: pod1\n",
+				},
+				{
+					names:   []string{"pod2"},
+					options: map[string]string{"-o": "yaml", "-n": "kube-system"},
+					out:     "kind: Pod\nmetadata:\n  name: pod2\n",
+				},
+			},
+			want: "kind: Pod\nmetadata:\n  name: pod1\n---\nkind: Pod\nmetadata:\n  name: pod2\n",
+		},
+		{
+			name: "jsonpath",
+			output: output{
+				format:    "jsonpath={.items[*].metadata.uid}",
+				delimiter: "\x00",
+			},
+			objects: []resourceObject{
+				{name: "pod1"},
+				{name: "pod2"},
+			},
+			kubectlRuns: []kubectlRun{
+				{
+					names:   []string{"pod1", "pod2"},
+					options: map[string]string{"-o": "jsonpath={.items[*].metadata.uid}"},
+					out:     "uid1 uid2",
+				},
+			},
+			want: "uid1 uid2\x00",
+		},
+		{
+			name: "kubectl error",
+			output: output{
+				format:    outputFormatJSON,
+				delimiter: "\n",
+			},
+			objects: []resourceObject{
+				{name: "pod1"},
+			},
+			kubectlRuns: []kubectlRun{
+				{
+					names:   []string{"pod1"},
+					options: map[string]string{"-o": "json"},
+					err:     defaultErr,
+				},
+			},
+			wantErr: defaultErr,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockKubectl := NewMockKubectl(mockCtrl)
+			for _, run := range tc.kubectlRuns {
+				mockKubectl.EXPECT().
+					run(gomock.Any(), "get", run.names, run.options).
+					Return([]byte(run.out), run.err).
+					Times(1)
+			}
+
+			got, gotErr := tc.output.formatObjects(context.Background(), mockKubectl, tc.objects)
+			assert.Equal(t, tc.want, string(got))
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
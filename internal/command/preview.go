@@ -0,0 +1,300 @@
+package command
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	previewFormatJSON          = "json"
+	previewFormatJSONPath      = "jsonpath"
+	previewFormatCustomColumns = "custom-columns"
+	previewFormatLogs          = "logs"
+	previewFormatEvents        = "events"
+	previewFormatTop           = "top"
+	previewFormatTemplate      = "template"
+	previewFormatSecret        = "secret"
+
+	previewLogsTail = 100
+	// previewLabelPreviousLogs is the preview label of logs of previous containers
+	previewLabelPreviousLogs = "previous-logs"
+)
+
+var (
+	errorInvalidArgumentFZFPreviewCommand = errors.New("preview format must be one of [describe, yaml, json, jsonpath=..., custom-columns=..., logs, events, top, template=..., secret]")
+	errorInvalidArgumentLogsTail          = errors.New("tail of logs must be -1 or more")
+	errorInvalidArgumentLogsSince         = errors.New("since of logs must be a positive duration like 5m")
+
+	kubernetesResourceNamesPod  = []string{"pods", "pod", "po"}
+	kubernetesResourceNamesNode = []string{"nodes", "node", "no"}
+	// kubernetesResourceNamesWorkload are workloads whose logs are shown for one of their pods by kubectl logs kind/name
+	kubernetesResourceNamesWorkload = []string{
+		"deployments", "deployment", "deploy", "deployments.apps", "deployment.apps",
+		"statefulsets", "statefulset", "sts", "statefulsets.apps", "statefulset.apps",
+		"daemonsets", "daemonset", "ds", "daemonsets.apps", "daemonset.apps",
+		"replicasets", "replicaset", "rs", "replicasets.apps", "replicaset.apps",
+		"jobs", "job", "jobs.batch", "job.batch",
+	}
+
+	// defaultLogsPreviewOptions are options of the logs preview unless they're specified
+	defaultLogsPreviewOptions = LogsPreviewOptions{
+		Tail: previewLogsTail,
+	}
+
+	// previewFormats are formats of the preview.
+	// A format with a value is specified like jsonpath={.metadata.name}
+	previewFormats = map[string]previewFormat{
+		kubectlOutputFormatDescribe: {
+			hidesSecretValues: true,
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return k.getCommand("describe", target.resource, []string{target.name}, target.options(nil)), nil
+			},
+		},
+		kubectlOutputFormatYaml: {
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return k.getCommand("get", target.resource, []string{target.name}, target.options(map[string]string{
+					"-o": "yaml",
+				})), nil
+			},
+		},
+		previewFormatJSON: {
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return k.getCommand("get", target.resource, []string{target.name}, target.options(map[string]string{
+					"-o": "json",
+				})), nil
+			},
+		},
+		previewFormatJSONPath: {
+			hasValue: true,
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return k.getCommand("get", target.resource, []string{target.name}, target.options(map[string]string{
+					"-o": "jsonpath=" + value,
+				})), nil
+			},
+		},
+		previewFormatCustomColumns: {
+			hasValue: true,
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return k.getCommand("get", target.resource, []string{target.name}, target.options(map[string]string{
+					"-o": "custom-columns=" + value,
+				})), nil
+			},
+		},
+		previewFormatLogs: {
+			resources: append(append([]string{}, kubernetesResourceNamesPod...), kubernetesResourceNamesWorkload...),
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return getLogsPreviewCommand(k, target, config.logsPreview, config.logsPreview.Previous), nil
+			},
+		},
+		previewFormatEvents: {
+			hidesSecretValues: true,
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				// Events are matched by uid, because the same name can be used for different kinds
+				uidCommand := k.getCommand("get", target.resource, []string{target.name}, target.options(map[string]string{
+					"-o": "jsonpath={.metadata.uid}",
+				}))
+				eventsCommand := k.getCommand("get", "events", nil, target.options(nil))
+				return fmt.Sprintf("%s --field-selector=involvedObject.uid=$(%s)", eventsCommand, uidCommand), nil
+			},
+		},
+		previewFormatTop: {
+			resources: append(append([]string{}, kubernetesResourceNamesPod...), kubernetesResourceNamesNode...),
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return k.getCommand("top", target.resource, []string{target.name}, target.options(nil)), nil
+			},
+		},
+		previewFormatTemplate: {
+			hasValue: true,
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				tmpl, err := template.New("preview").Parse(value)
+				if err != nil {
+					return "", fmt.Errorf("failed to parse the template of preview: %w", err)
+				}
+				var command bytes.Buffer
+				if err := tmpl.Execute(&command, previewTemplateData{
+					Kubectl:   k.getCommandName(),
+					Resource:  k.resource,
+					Name:      target.name,
+					Namespace: target.namespace,
+				}); err != nil {
+					return "", fmt.Errorf("failed to execute the template of preview: %w", err)
+				}
+				return command.String(), nil
+			},
+		},
+		previewFormatSecret: {
+			resources:         kubernetesResourceNamesSecret,
+			hidesSecretValues: true,
+			command: func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error) {
+				return getSecretPreviewCommand(k, target, false)
+			},
+		},
+	}
+)
+
+// LogsPreviewOptions are options of kubectl logs for the logs preview
+type LogsPreviewOptions struct {
+	// Tail is the number of lines of recent logs. All lines are shown if it's -1
+	Tail int
+	// Since shows only logs newer than the relative duration like 5m. It's ignored if it's empty
+	Since string
+	// Previous shows logs of the previous instance of containers at first.
+	// Logs of current and previous ones can be toggled by a key on fzf
+	Previous bool
+}
+
+func (o LogsPreviewOptions) validate() error {
+	if o.Tail < -1 {
+		return errorInvalidArgumentLogsTail
+	}
+	if o.Since != "" {
+		since, err := time.ParseDuration(o.Since)
+		if err != nil || since <= 0 {
+			return errorInvalidArgumentLogsSince
+		}
+	}
+	return nil
+}
+
+// options returns options of kubectl logs, with logs of previous containers if previous is true
+func (o LogsPreviewOptions) options(previous bool) map[string]string {
+	options := map[string]string{
+		"--tail": strconv.Itoa(o.Tail),
+	}
+	if o.Since != "" {
+		options["--since"] = o.Since
+	}
+	if previous {
+		options["--previous"] = "true"
+	}
+	return options
+}
+
+// getLogsPreviewCommand returns the command of the logs preview, in which each line is prefixed by the pod and the container.
+// Logs of a workload like a deployment are shown for one of its pods.
+func getLogsPreviewCommand(k *kubectl, target previewTarget, logsOptions LogsPreviewOptions, previous bool) string {
+	name := target.name
+	if !isPodResource(k) {
+		name = k.resource + "/" + target.name
+	}
+	options := logsOptions.options(previous)
+	options["--all-containers"] = "true"
+	options["--prefix"] = "true"
+	return k.getCommand("logs", "", []string{name}, target.options(options))
+}
+
+type previewFormat struct {
+	// hasValue is true if the format requires a value after "="
+	hasValue bool
+	// resources are the names of resources supporting the format. All resources are supported if it's empty
+	resources []string
+	// hidesSecretValues is true if values of secrets are not shown, which is required to preview secrets
+	// when they cannot be revealed by the config
+	hidesSecretValues bool
+	command           func(k *kubectl, target previewTarget, value string, config *resolvedConfig) (string, error)
+}
+
+// previewTarget has fzf placeholders for the row in the preview command
+type previewTarget struct {
+	// resource is empty for multiple resources, because the name includes the kind
+	resource string
+	name     string
+	// namespace is the placeholder across all namespaces, or the namespace of kubectl
+	namespace     string
+	allNamespaces bool
+}
+
+// previewTemplateData is the data for the template preview format
+type previewTemplateData struct {
+	Kubectl   string
+	Resource  string
+	Name      string
+	Namespace string
+}
+
+func newPreviewTarget(k *kubectl) previewTarget {
+	target := previewTarget{
+		resource:  k.resource,
+		name:      "{1}",
+		namespace: k.namespace,
+	}
+	if k.hasMultipleResources() {
+		target.resource = ""
+	}
+	if k.allNamespaces {
+		// The 1st column is the namespace and the 2nd is the name
+		target.name = "{2}"
+		target.namespace = "{1}"
+		target.allNamespaces = true
+	}
+	return target
+}
+
+// options returns options with the namespace of the row across all namespaces
+func (t previewTarget) options(options map[string]string) map[string]string {
+	if !t.allNamespaces {
+		return options
+	}
+	merged := map[string]string{
+		"-n": t.namespace,
+	}
+	for k, v := range options {
+		merged[k] = v
+	}
+	return merged
+}
+
+// getPreviewCommand returns the command of the preview format for the resource of kubectl.
+func getPreviewCommand(k *kubectl, format string, config *resolvedConfig) (string, error) {
+	previewFormat, name, value, err := parsePreviewFormat(format)
+	if err != nil {
+		return "", err
+	}
+	if len(previewFormat.resources) > 0 && !previewFormat.supports(k) {
+		return "", fmt.Errorf("preview format %s is not supported for %s", name, k.resource)
+	}
+	if !previewFormat.hidesSecretValues && config.secretRevealDisabled && hasSecretResource(k.resource) {
+		return "", fmt.Errorf("preview format %s cannot be used for %s: %w", name, k.resource, errorSecretRevealDisabled)
+	}
+	return previewFormat.command(k, newPreviewTarget(k), value, config)
+}
+
+// parsePreviewFormat returns the preview format with its name and value, like jsonpath and {.metadata.name}
+func parsePreviewFormat(format string) (previewFormat, string, string, error) {
+	name := format
+	value := ""
+	hasValue := false
+	if i := strings.Index(format, "="); i >= 0 {
+		name = format[:i]
+		value = format[i+1:]
+		hasValue = true
+	}
+	previewFormat, ok := previewFormats[name]
+	if !ok || previewFormat.hasValue != hasValue {
+		return previewFormat, "", "", errorInvalidArgumentFZFPreviewCommand
+	}
+	return previewFormat, name, value, nil
+}
+
+// hidesSecretValues returns true if the preview format doesn't show values of secrets
+func hidesSecretValues(format string) bool {
+	previewFormat, _, _, err := parsePreviewFormat(format)
+	return err == nil && previewFormat.hidesSecretValues
+}
+
+func (f previewFormat) supports(k *kubectl) bool {
+	if k.hasMultipleResources() {
+		return false
+	}
+	for _, resource := range f.resources {
+		if strings.ToLower(k.resource) == resource {
+			return true
+		}
+	}
+	return false
+}
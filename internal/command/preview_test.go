@@ -0,0 +1,274 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetPreviewCommand(t *testing.T) {
+	backupExecutable := executable
+	defer func() {
+		executable = backupExecutable
+	}()
+	executable = func() (string, error) {
+		return "/usr/local/bin/kubectl-fzf", nil
+	}
+
+	testCases := []struct {
+		name    string
+		kubectl *kubectl
+		format  string
+		want    string
+		wantErr error
+	}{
+		{
+			name: "describe",
+			kubectl: &kubectl{
+				resource:  kubernetesResourcePods,
+				namespace: "default",
+			},
+			format: kubectlOutputFormatDescribe,
+			want:   "kubectl describe pods {1} -n=default",
+		},
+		{
+			name: "yaml across all namespaces",
+			kubectl: &kubectl{
+				resource:      kubernetesResourcePods,
+				allNamespaces: true,
+			},
+			format: kubectlOutputFormatYaml,
+			want:   "kubectl get pods {2} -n={1} -o=yaml",
+		},
+		{
+			name: "json for multiple resources",
+			kubectl: &kubectl{
+				resource: kubernetesResourceAll,
+			},
+			format: previewFormatJSON,
+			want:   "kubectl get {1} -o=json",
+		},
+		{
+			name: "jsonpath",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			format: "jsonpath={.spec.containers[*].image}",
+			want:   "kubectl get pods {1} '-o=jsonpath={.spec.containers[*].image}'",
+		},
+		{
+			name: "custom-columns",
+			kubectl: &kubectl{
+				resource: "deployments",
+			},
+			format: "custom-columns=NAME:.metadata.name,IMAGE:.spec.template.spec.containers[*].image",
+			want:   "kubectl get deployments {1} '-o=custom-columns=NAME:.metadata.name,IMAGE:.spec.template.spec.containers[*].image'",
+		},
+		{
+			name: "logs",
+			kubectl: &kubectl{
+				resource:  "po",
+				namespace: "default",
+			},
+			format: previewFormatLogs,
+			want:   "kubectl logs {1} -n=default --all-containers=true --prefix=true --tail=100",
+		},
+		{
+			name: "logs of a deployment across all namespaces",
+			kubectl: &kubectl{
+				resource:      "deploy",
+				allNamespaces: true,
+			},
+			format: previewFormatLogs,
+			want:   "kubectl logs deploy/{2} --all-containers=true --prefix=true --tail=100 -n={1}",
+		},
+		{
+			name: "events across all namespaces",
+			kubectl: &kubectl{
+				resource:      "deployments",
+				allNamespaces: true,
+			},
+			format: previewFormatEvents,
+			want:   "kubectl get events -n={1} --field-selector=involvedObject.uid=$(kubectl get deployments {2} -n={1} -o=jsonpath={.metadata.uid})",
+		},
+		{
+			name: "top nodes",
+			kubectl: &kubectl{
+				resource: "nodes",
+			},
+			format: previewFormatTop,
+			want:   "kubectl top nodes {1}",
+		},
+		{
+			name: "template",
+			kubectl: &kubectl{
+				resource:      kubernetesResourcePods,
+				allNamespaces: true,
+			},
+			format: "template={{.Kubectl}} get {{.Resource}} {{.Name}} -n {{.Namespace}} -o wide",
+			want:   "kubectl get pods {2} -n {1} -o wide",
+		},
+		{
+			name: "template with context",
+			kubectl: &kubectl{
+				resource:    kubernetesResourcePods,
+				namespace:   "default",
+				kubeContext: "kind-kind",
+			},
+			format: "template={{.Kubectl}} get {{.Resource}} {{.Name}} -n {{.Namespace}}",
+			want:   "kubectl --context=kind-kind get pods {1} -n default",
+		},
+		{
+			name: "secret across all namespaces",
+			kubectl: &kubectl{
+				resource:      "secrets",
+				allNamespaces: true,
+			},
+			format: previewFormatSecret,
+			want:   "kubectl get secrets {2} -n={1} -o=json | /usr/local/bin/kubectl-fzf decode-secret",
+		},
+		{
+			name: "secret for an unsupported resource",
+			kubectl: &kubectl{
+				resource: "configmaps",
+			},
+			format:  previewFormatSecret,
+			wantErr: errors.New("preview format secret is not supported for configmaps"),
+		},
+		{
+			name: "invalid template",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			format:  "template={{.Kubectl",
+			wantErr: errors.New("failed to parse the template of preview: template: preview:1: unclosed action"),
+		},
+		{
+			name: "logs for an unsupported resource",
+			kubectl: &kubectl{
+				resource: "services",
+			},
+			format:  previewFormatLogs,
+			wantErr: errors.New("preview format logs is not supported for services"),
+		},
+		{
+			name: "top for multiple resources",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods + "," + kubernetesResourceService,
+			},
+			format:  previewFormatTop,
+			wantErr: errors.New("preview format top is not supported for pods,svc"),
+		},
+		{
+			name: "jsonpath without a value",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			format:  previewFormatJSONPath,
+			wantErr: errorInvalidArgumentFZFPreviewCommand,
+		},
+		{
+			name: "describe with a value",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			format:  "describe=pods",
+			wantErr: errorInvalidArgumentFZFPreviewCommand,
+		},
+		{
+			name: "unknown format",
+			kubectl: &kubectl{
+				resource: kubernetesResourcePods,
+			},
+			format:  "wide",
+			wantErr: errorInvalidArgumentFZFPreviewCommand,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, err := newResolvedConfig(nil, nil)
+			require.NoError(t, err)
+			got, gotErr := getPreviewCommand(tc.kubectl, tc.format, config)
+			assert.Equal(t, tc.want, got)
+			if tc.wantErr == nil {
+				assert.NoError(t, gotErr)
+				return
+			}
+			assert.EqualError(t, gotErr, tc.wantErr.Error())
+		})
+	}
+}
+
+func TestGetPreviewCommand_logsOptions(t *testing.T) {
+	testCases := []struct {
+		name    string
+		options LogsPreviewOptions
+		want    string
+		wantErr error
+	}{
+		{
+			name:    "all lines of previous containers",
+			options: LogsPreviewOptions{Tail: -1, Previous: true},
+			want:    "kubectl logs {1} --all-containers=true --prefix=true --previous=true --tail=-1",
+		},
+		{
+			name:    "logs since the duration",
+			options: LogsPreviewOptions{Tail: 10, Since: "5m"},
+			want:    "kubectl logs {1} --all-containers=true --prefix=true --since=5m --tail=10",
+		},
+		{
+			name:    "invalid tail",
+			options: LogsPreviewOptions{Tail: -2},
+			wantErr: errorInvalidArgumentLogsTail,
+		},
+		{
+			name:    "invalid since",
+			options: LogsPreviewOptions{Tail: 10, Since: "yesterday"},
+			wantErr: errorInvalidArgumentLogsSince,
+		},
+		{
+			name:    "negative since",
+			options: LogsPreviewOptions{Tail: 10, Since: "-5m"},
+			wantErr: errorInvalidArgumentLogsSince,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config, gotErr := newResolvedConfig(nil, &tc.options)
+			assert.Equal(t, tc.wantErr, gotErr)
+			if tc.wantErr != nil {
+				return
+			}
+			got, err := getPreviewCommand(&kubectl{resource: kubernetesResourcePods}, previewFormatLogs, config)
+			require.NoError(t, err)
+			assert.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestGetPreviewCommand_secretRevealDisabled(t *testing.T) {
+	backupExecutable := executable
+	defer func() {
+		executable = backupExecutable
+	}()
+	executable = func() (string, error) {
+		return "/usr/local/bin/kubectl-fzf", nil
+	}
+
+	config, err := newResolvedConfig(&Config{DisableSecretReveal: true}, nil)
+	require.NoError(t, err)
+	for _, format := range []string{kubectlOutputFormatDescribe, previewFormatEvents, previewFormatSecret} {
+		_, err := getPreviewCommand(&kubectl{resource: "secrets"}, format, config)
+		assert.NoError(t, err, format)
+	}
+	for _, format := range []string{kubectlOutputFormatYaml, previewFormatJSON, "jsonpath={.data}", "custom-columns=DATA:.data", "template={{.Kubectl}} get secret {{.Name}} -o yaml"} {
+		_, err := getPreviewCommand(&kubectl{resource: "secrets"}, format, config)
+		assert.True(t, errors.Is(err, errorSecretRevealDisabled), format)
+		_, err = getPreviewCommand(&kubectl{resource: "pods,secret"}, format, config)
+		assert.True(t, errors.Is(err, errorSecretRevealDisabled), format)
+		_, err = getPreviewCommand(&kubectl{resource: "configmaps"}, format, config)
+		assert.NoError(t, err, format)
+	}
+}
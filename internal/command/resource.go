@@ -0,0 +1,144 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+var errorNoAPIResources = errors.New("failed to run kubectl api-resources. There are no resources")
+
+type resourceCli struct {
+	kubectl *kubectl
+	finder  finder
+	fzfArgs []string
+}
+
+// apiResource is a row of "kubectl api-resources"
+type apiResource struct {
+	name       string
+	shortNames string
+	apiVersion string
+	namespaced string
+	kind       string
+}
+
+// qualifiedName returns the name with the group like deployments.apps, so that resources of CRDs with the same name are distinguished
+func (r apiResource) qualifiedName() string {
+	if i := strings.Index(r.apiVersion, "/"); i >= 0 {
+		return r.name + "." + r.apiVersion[:i]
+	}
+	return r.name
+}
+
+// NewResourceCli returns the cli to select kinds of resources on fzf from "kubectl api-resources".
+// finderName is one of FinderAuto, FinderFzf or FinderBuiltin.
+func NewResourceCli(kubeContext string, finderName string, config *Config) (*resourceCli, error) {
+	finder, err := newFinder(finderName)
+	if err != nil {
+		return nil, err
+	}
+	k := &kubectl{
+		kubeContext: kubeContext,
+	}
+	previewCommand := k.getCommand("explain", "{1}", nil, nil)
+	fzfOptions, err := newFzfOptions(previewCommand, true, config.fzfBindings())
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fzf option: %w", err)
+	}
+	return &resourceCli{
+		kubectl: k,
+		finder:  finder,
+		fzfArgs: fzfOptions.args(),
+	}, nil
+}
+
+// SelectResource returns the resource selected on fzf, or resources joined by "," if multiple ones are selected.
+// It returns ErrCanceled if fzf is canceled, or ErrNoMatch if no resources match the query.
+func (c resourceCli) SelectResource(ctx context.Context, ioErr io.Writer) (string, error) {
+	args := c.kubectl.getArguments("api-resources", "", nil, map[string]string{
+		"--verbs": "list",
+	})
+	out, err := runKubectl(ctx, args)
+	if err != nil {
+		return "", newKubectlError(args, out, err)
+	}
+	resources := parseAPIResources(string(out))
+	if len(resources) == 0 {
+		return "", errorNoAPIResources
+	}
+
+	var candidates bytes.Buffer
+	writer := tabwriter.NewWriter(&candidates, 6, 4, 3, ' ', 0)
+	fmt.Fprintln(writer, "RESOURCE\tSHORTNAMES\tNAMESPACED\tKIND")
+	for _, resource := range resources {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", resource.qualifiedName(), resource.shortNames, resource.namespaced, resource.kind)
+	}
+	if err := writer.Flush(); err != nil {
+		return "", fmt.Errorf("failed to write api resources: %w", err)
+	}
+
+	out, err = c.finder.run(ctx, c.fzfArgs, &candidates, ioErr)
+	if err != nil {
+		if isFinderCanceled(err) {
+			return "", ErrCanceled
+		}
+		if isFinderNoMatch(err) {
+			return "", ErrNoMatch
+		}
+		return "", fmt.Errorf("failed to run the command %s: %w", getFzfCommandLine(c.fzfArgs), err)
+	}
+	var names []string
+	for _, row := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		columns := strings.Fields(row)
+		if len(columns) == 0 {
+			continue
+		}
+		names = append(names, columns[0])
+	}
+	return strings.Join(names, ","), nil
+}
+
+// parseAPIResources parses the output of "kubectl api-resources".
+// Columns are split by the positions of headers, because SHORTNAMES and APIVERSION can be empty.
+func parseAPIResources(out string) []apiResource {
+	rows := strings.Split(strings.TrimRight(out, "\n"), "\n")
+	header := rows[0]
+	columnNames := []string{"NAME", "SHORTNAMES", "APIVERSION", "NAMESPACED", "KIND"}
+	positions := make([]int, len(columnNames))
+	for i, columnName := range columnNames {
+		positions[i] = strings.Index(header, columnName)
+		if positions[i] < 0 {
+			return nil
+		}
+	}
+	column := func(row string, i int) string {
+		if positions[i] >= len(row) {
+			return ""
+		}
+		end := len(row)
+		if i+1 < len(positions) && positions[i+1] < end {
+			end = positions[i+1]
+		}
+		return strings.TrimSpace(row[positions[i]:end])
+	}
+
+	var resources []apiResource
+	for _, row := range rows[1:] {
+		if strings.TrimSpace(row) == "" {
+			continue
+		}
+		resources = append(resources, apiResource{
+			name:       column(row, 0),
+			shortNames: column(row, 1),
+			apiVersion: column(row, 2),
+			namespaced: column(row, 3),
+			kind:       column(row, 4),
+		})
+	}
+	return resources
+}
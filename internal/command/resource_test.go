@@ -0,0 +1,152 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os/exec"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const apiResourcesOutput = `NAME                SHORTNAMES   APIVERSION                NAMESPACED   KIND
+configmaps          cm           v1                        true         ConfigMap
+namespaces          ns           v1                        false        Namespace
+pods                po           v1                        true         Pod
+deployments         deploy       apps/v1                   true         Deployment
+certificates        cert,certs   cert-manager.io/v1        true         Certificate
+widgets                          example.com/v1alpha1      true         Widget
+`
+
+func TestParseAPIResources(t *testing.T) {
+	testCases := []struct {
+		name string
+		out  string
+		want []apiResource
+	}{
+		{
+			name: "core, groups and CRDs without short names",
+			out:  apiResourcesOutput,
+			want: []apiResource{
+				{name: "configmaps", shortNames: "cm", apiVersion: "v1", namespaced: "true", kind: "ConfigMap"},
+				{name: "namespaces", shortNames: "ns", apiVersion: "v1", namespaced: "false", kind: "Namespace"},
+				{name: "pods", shortNames: "po", apiVersion: "v1", namespaced: "true", kind: "Pod"},
+				{name: "deployments", shortNames: "deploy", apiVersion: "apps/v1", namespaced: "true", kind: "Deployment"},
+				{name: "certificates", shortNames: "cert,certs", apiVersion: "cert-manager.io/v1", namespaced: "true", kind: "Certificate"},
+				{name: "widgets", shortNames: "", apiVersion: "example.com/v1alpha1", namespaced: "true", kind: "Widget"},
+			},
+		},
+		{
+			name: "only header",
+			out:  "NAME   SHORTNAMES   APIVERSION   NAMESPACED   KIND\n",
+			want: nil,
+		},
+		{
+			name: "unknown header",
+			out:  "error: the server doesn't have a resource type\n",
+			want: nil,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, parseAPIResources(tc.out))
+		})
+	}
+}
+
+func TestResourceCli_SelectResource(t *testing.T) {
+	backupRunKubectl := runKubectl
+	backupRunCommandWithFzf := runCommandWithFzf
+	defer func() {
+		runKubectl = backupRunKubectl
+		runCommandWithFzf = backupRunCommandWithFzf
+	}()
+
+	wantFzfInput := `RESOURCE                       SHORTNAMES   NAMESPACED   KIND
+configmaps                     cm           true         ConfigMap
+namespaces                     ns           false        Namespace
+pods                           po           true         Pod
+deployments.apps               deploy       true         Deployment
+certificates.cert-manager.io   cert,certs   true         Certificate
+widgets.example.com                         true         Widget
+`
+	testCases := []struct {
+		name       string
+		kubectlOut string
+		kubectlErr error
+		fzfOut     string
+		fzfErr     error
+		want       string
+		wantErr    error
+	}{
+		{
+			name:       "a resource in a group",
+			kubectlOut: apiResourcesOutput,
+			fzfOut:     "deployments.apps               deploy       true         Deployment\n",
+			want:       "deployments.apps",
+		},
+		{
+			name:       "multiple resources",
+			kubectlOut: apiResourcesOutput,
+			fzfOut:     "pods   po   true   Pod\nwidgets.example.com   true   Widget\n",
+			want:       "pods,widgets.example.com",
+		},
+		{
+			name:       "fzf is canceled",
+			kubectlOut: apiResourcesOutput,
+			fzfErr:     newExitError(t, 130),
+			wantErr:    ErrCanceled,
+		},
+		{
+			name:       "no resources match the query",
+			kubectlOut: apiResourcesOutput,
+			fzfErr:     newExitError(t, 1),
+			wantErr:    ErrNoMatch,
+		},
+		{
+			name:       "kubectl error",
+			kubectlOut: "error: unable to connect\n",
+			kubectlErr: errors.New("exit status 1"),
+			wantErr: &KubectlError{
+				Args:   []string{"api-resources", "--verbs=list"},
+				Stderr: "error: unable to connect\n",
+				Err:    errors.New("exit status 1"),
+			},
+		},
+		{
+			name:       "no resources",
+			kubectlOut: "NAME   SHORTNAMES   APIVERSION   NAMESPACED   KIND\n",
+			wantErr:    errorNoAPIResources,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			runKubectl = func(ctx context.Context, args []string) ([]byte, error) {
+				assert.Equal(t, []string{"api-resources", "--verbs=list"}, args)
+				return []byte(tc.kubectlOut), tc.kubectlErr
+			}
+			runCommandWithFzf = func(ctx context.Context, args []string, ioIn io.Reader, ioErr io.Writer) ([]byte, error) {
+				got, err := io.ReadAll(ioIn)
+				require.NoError(t, err)
+				assert.Equal(t, wantFzfInput, string(got))
+				return []byte(tc.fzfOut), tc.fzfErr
+			}
+
+			sut, err := NewResourceCli("", FinderFzf, &Config{})
+			require.NoError(t, err)
+			got, gotErr := sut.SelectResource(context.Background(), io.Discard)
+			assert.Equal(t, tc.want, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
+
+// newExitError returns the error of a command exiting with the code
+func newExitError(t *testing.T, code int) error {
+	err := exec.Command("sh", "-c", "exit "+strconv.Itoa(code)).Run()
+	require.Error(t, err)
+	return err
+}
@@ -0,0 +1,211 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// previewLabelRevealedSecret is the preview label of the secret preview with values
+	previewLabelRevealedSecret = "revealed-secret"
+	// secretMask is shown instead of values of secrets unless they're revealed
+	secretMask = "********"
+)
+
+var (
+	errorSecretRevealDisabled = errors.New("values of secrets cannot be revealed by disableSecretReveal of the config file")
+
+	kubernetesResourceNamesSecret = []string{"secrets", "secret"}
+
+	// executable returns the path of kubectl-fzf, which is run in preview commands
+	executable = os.Executable
+	// timeNow returns the current time to show the expiry of certificates
+	timeNow = time.Now
+)
+
+// kubernetesSecret is a secret of "kubectl get secret -o json". Values of data are decoded from base64
+type kubernetesSecret struct {
+	Metadata struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"metadata"`
+	Type string            `json:"type"`
+	Data map[string][]byte `json:"data"`
+}
+
+type secretCli struct {
+	reveal bool
+}
+
+// NewSecretCli returns the cli to show a secret in JSON from the input with decoded values for the secret preview.
+// Values are masked unless reveal is true, and they cannot be revealed if it's disabled by the config.
+func NewSecretCli(reveal bool, config *Config) (*secretCli, error) {
+	if reveal && config.DisableSecretReveal {
+		return nil, errorSecretRevealDisabled
+	}
+	return &secretCli{
+		reveal: reveal,
+	}, nil
+}
+
+// Run shows each value of data with its size and the kind, like a certificate with its subject and expiry, or binary data.
+func (c secretCli) Run(ctx context.Context, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	in, err := io.ReadAll(ioIn)
+	if err != nil {
+		return fmt.Errorf("failed to read the secret: %w", err)
+	}
+	var secret kubernetesSecret
+	if err := json.Unmarshal(in, &secret); err != nil {
+		return fmt.Errorf("failed to parse the secret: %w", err)
+	}
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "Name:      %s\n", secret.Metadata.Name)
+	fmt.Fprintf(&out, "Namespace: %s\n", secret.Metadata.Namespace)
+	fmt.Fprintf(&out, "Type:      %s\n", secret.Type)
+	keys := make([]string, 0, len(secret.Data))
+	for key := range secret.Data {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		out.WriteString("\n")
+		c.writeValue(&out, key, secret.Data[key])
+	}
+	if _, err := ioOut.Write(out.Bytes()); err != nil {
+		return fmt.Errorf("failed to output the secret: %w", err)
+	}
+	return nil
+}
+
+// writeValue writes the value of the key. Certificates are written with their information even if values are masked,
+// because they're not secret.
+func (c secretCli) writeValue(out *bytes.Buffer, key string, value []byte) {
+	certificates := parseCertificates(value)
+	binary := !isText(value)
+	switch {
+	case len(certificates) > 0:
+		fmt.Fprintf(out, "%s: certificate, %d bytes\n", key, len(value))
+		for _, certificate := range certificates {
+			writeCertificate(out, certificate)
+		}
+	case binary:
+		fmt.Fprintf(out, "%s: binary, %d bytes\n", key, len(value))
+	default:
+		fmt.Fprintf(out, "%s: %d bytes\n", key, len(value))
+	}
+	if binary {
+		return
+	}
+	if !c.reveal {
+		fmt.Fprintf(out, "  %s\n", secretMask)
+		return
+	}
+	for _, line := range strings.Split(strings.TrimRight(string(value), "\n"), "\n") {
+		fmt.Fprintf(out, "  %s\n", line)
+	}
+}
+
+// parseCertificates returns certificates in PEM blocks, or a DER certificate
+func parseCertificates(value []byte) []*x509.Certificate {
+	var certificates []*x509.Certificate
+	rest := value
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		if certificate, err := x509.ParseCertificate(block.Bytes); err == nil {
+			certificates = append(certificates, certificate)
+		}
+	}
+	if len(certificates) > 0 || isText(value) {
+		return certificates
+	}
+	if certificate, err := x509.ParseCertificate(value); err == nil {
+		return []*x509.Certificate{certificate}
+	}
+	return nil
+}
+
+func writeCertificate(out *bytes.Buffer, certificate *x509.Certificate) {
+	fmt.Fprintf(out, "  Subject:   %s\n", certificate.Subject)
+	fmt.Fprintf(out, "  Issuer:    %s\n", certificate.Issuer)
+	if len(certificate.DNSNames) > 0 {
+		fmt.Fprintf(out, "  DNS names: %s\n", strings.Join(certificate.DNSNames, ", "))
+	}
+	fmt.Fprintf(out, "  Not after: %s (%s)\n", certificate.NotAfter.UTC().Format(time.RFC3339), getExpiry(certificate.NotAfter))
+}
+
+// getExpiry returns the days until the time or since it
+func getExpiry(notAfter time.Time) string {
+	now := timeNow()
+	if notAfter.Before(now) {
+		return fmt.Sprintf("expired %d days ago", int(now.Sub(notAfter).Hours()/24))
+	}
+	return fmt.Sprintf("expires in %d days", int(notAfter.Sub(now).Hours()/24))
+}
+
+// isText returns true if the value is UTF-8 without control characters except for tabs and newlines
+func isText(value []byte) bool {
+	if !utf8.Valid(value) {
+		return false
+	}
+	for _, r := range string(value) {
+		if unicode.IsControl(r) && r != '\t' && r != '\n' && r != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// isSecretResource returns true if the resource is secrets
+func isSecretResource(resource string) bool {
+	for _, name := range kubernetesResourceNamesSecret {
+		if strings.ToLower(resource) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// hasSecretResource returns true if secrets are one of resources joined by ","
+func hasSecretResource(resource string) bool {
+	for _, r := range strings.Split(resource, ",") {
+		if isSecretResource(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// getSecretPreviewCommand returns the command of the secret preview, which decodes the secret by kubectl-fzf
+func getSecretPreviewCommand(k *kubectl, target previewTarget, reveal bool) (string, error) {
+	path, err := executable()
+	if err != nil {
+		return "", fmt.Errorf("failed to find the path of kubectl-fzf: %w", err)
+	}
+	command := k.getCommand("get", target.resource, []string{target.name}, target.options(map[string]string{
+		"-o": "json",
+	})) + " | " + quoteArgument(path) + " decode-secret"
+	if reveal {
+		command += " --reveal"
+	}
+	return command, nil
+}
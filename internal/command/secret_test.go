@@ -0,0 +1,162 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCertificate(t *testing.T, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "example.com"},
+		DNSNames:     []string{"example.com", "www.example.com"},
+		NotBefore:    notAfter.AddDate(-1, 0, 0),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	return der
+}
+
+func newTestSecretJSON(t *testing.T, data map[string][]byte) string {
+	encoded := make(map[string]string, len(data))
+	for key, value := range data {
+		encoded[key] = base64.StdEncoding.EncodeToString(value)
+	}
+	out, err := json.Marshal(map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]string{
+			"name":      "secret1",
+			"namespace": "default",
+		},
+		"type": "Opaque",
+		"data": encoded,
+	})
+	require.NoError(t, err)
+	return string(out)
+}
+
+func TestSecretCli_Run(t *testing.T) {
+	backupTimeNow := timeNow
+	defer func() {
+		timeNow = backupTimeNow
+	}()
+	now := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time {
+		return now
+	}
+
+	der := newTestCertificate(t, now.AddDate(0, 0, 30))
+	certificate := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	expiredDER := newTestCertificate(t, now.AddDate(0, 0, -3))
+	data := map[string][]byte{
+		"password": []byte("hunter2"),
+		"config":   []byte("a: 1\nb: 2\n"),
+		"tls.crt":  certificate,
+		"ca.der":   expiredDER,
+		"blob":     {0x00, 0xff, 0x01},
+	}
+	certificateInfo := `  Subject:   CN=example.com
+  Issuer:    CN=example.com
+  DNS names: example.com, www.example.com
+`
+
+	testCases := []struct {
+		name   string
+		reveal bool
+		want   string
+	}{
+		{
+			name:   "masked values",
+			reveal: false,
+			want: fmt.Sprintf(`Name:      secret1
+Namespace: default
+Type:      Opaque
+
+blob: binary, 3 bytes
+
+ca.der: certificate, %d bytes
+%s  Not after: 2026-09-28T00:00:00Z (expired 3 days ago)
+
+config: 10 bytes
+  ********
+
+password: 7 bytes
+  ********
+
+tls.crt: certificate, %d bytes
+%s  Not after: 2026-10-31T00:00:00Z (expires in 30 days)
+  ********
+`, len(expiredDER), certificateInfo, len(certificate), certificateInfo),
+		},
+		{
+			name:   "revealed values",
+			reveal: true,
+			want: fmt.Sprintf(`Name:      secret1
+Namespace: default
+Type:      Opaque
+
+blob: binary, 3 bytes
+
+ca.der: certificate, %d bytes
+%s  Not after: 2026-09-28T00:00:00Z (expired 3 days ago)
+
+config: 10 bytes
+  a: 1
+  b: 2
+
+password: 7 bytes
+  hunter2
+
+tls.crt: certificate, %d bytes
+%s  Not after: 2026-10-31T00:00:00Z (expires in 30 days)
+%s`, len(expiredDER), certificateInfo, len(certificate), certificateInfo, indent(string(certificate))),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sut, err := NewSecretCli(tc.reveal, &Config{})
+			require.NoError(t, err)
+			var got bytes.Buffer
+			gotErr := sut.Run(context.Background(), bytes.NewBufferString(newTestSecretJSON(t, data)), &got, io.Discard)
+			assert.NoError(t, gotErr)
+			assert.Equal(t, tc.want, got.String())
+		})
+	}
+}
+
+// indent returns lines of s indented by 2 spaces
+func indent(s string) string {
+	var indented bytes.Buffer
+	for _, line := range bytes.Split(bytes.TrimRight([]byte(s), "\n"), []byte("\n")) {
+		indented.WriteString("  " + string(line) + "\n")
+	}
+	return indented.String()
+}
+
+func TestNewSecretCli(t *testing.T) {
+	_, err := NewSecretCli(false, &Config{DisableSecretReveal: true})
+	assert.NoError(t, err)
+	got, err := NewSecretCli(true, &Config{DisableSecretReveal: true})
+	assert.Nil(t, got)
+	assert.Equal(t, errorSecretRevealDisabled, err)
+}
@@ -0,0 +1,294 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	ShellBash = "bash"
+	ShellZsh  = "zsh"
+	ShellFish = "fish"
+
+	// DefaultShellPrefix is the prefix key of key bindings of shells
+	DefaultShellPrefix = "ctrl-x,ctrl-k"
+	// DefaultShellCompleteKey is the key after the prefix to select objects for the command line being typed
+	DefaultShellCompleteKey = "ctrl-f"
+)
+
+var (
+	// defaultShellBindings are keys after the prefix and resources selected by them.
+	// An empty resource selects the kind of resources at first
+	defaultShellBindings = map[string]string{
+		"ctrl-p": "pod",
+		"ctrl-d": "deployment",
+		"ctrl-s": "service",
+		"ctrl-c": "configmap",
+		"ctrl-h": "horizontalpodautoscaler",
+		"ctrl-a": "all",
+		"ctrl-k": "",
+	}
+
+	shellKeyPattern      = regexp.MustCompile(`^(ctrl|alt)-([a-z])$`)
+	shellResourcePattern = regexp.MustCompile(`^[a-zA-Z0-9.,/-]*$`)
+)
+
+// shellKey is a key like ctrl-p or alt-p
+type shellKey struct {
+	modifier string
+	char     string
+}
+
+// parseShellKeys parses keys joined by ",", like ctrl-x,ctrl-k
+func parseShellKeys(keys string) ([]shellKey, error) {
+	if keys == "" {
+		return nil, nil
+	}
+	var parsed []shellKey
+	for _, key := range strings.Split(keys, ",") {
+		matches := shellKeyPattern.FindStringSubmatch(key)
+		if matches == nil {
+			return nil, fmt.Errorf("key must be like ctrl-p or alt-p: %q", key)
+		}
+		parsed = append(parsed, shellKey{
+			modifier: matches[1],
+			char:     matches[2],
+		})
+	}
+	return parsed, nil
+}
+
+// shellBinding is a key binding to select objects of the resource
+type shellBinding struct {
+	keys     []shellKey
+	resource string
+	// name is used for the name of the widget of zsh
+	name string
+}
+
+type initCli struct {
+	shell    string
+	bindings []shellBinding
+	// completeKeys are keys to select objects by kubectl fzf complete. It's disabled if they're empty
+	completeKeys []shellKey
+}
+
+// NewInitCli returns the cli to write key bindings for the shell, which insert objects selected by kubectl fzf.
+// bindings are keys after the prefix and the resources selected by them, and an empty resource selects the kind of resources at first.
+// completeKey is the key after the prefix to select objects for the command line being typed, which is disabled if it's empty.
+func NewInitCli(shell string, prefix string, bindings map[string]string, completeKey string) (*initCli, error) {
+	switch shell {
+	case ShellBash, ShellZsh, ShellFish:
+	default:
+		return nil, fmt.Errorf("shell must be one of [%s, %s, %s]", ShellBash, ShellZsh, ShellFish)
+	}
+	prefixKeys, err := parseShellKeys(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("prefix: %w", err)
+	}
+
+	keys := make([]string, 0, len(bindings))
+	for key := range bindings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	shellBindings := make([]shellBinding, 0, len(keys))
+	for _, key := range keys {
+		resource := bindings[key]
+		bindingKeys, err := parseShellKeys(key)
+		if err != nil {
+			return nil, err
+		}
+		if len(bindingKeys) != 1 {
+			return nil, fmt.Errorf("key must be like ctrl-p or alt-p: %q", key)
+		}
+		if !shellResourcePattern.MatchString(resource) {
+			return nil, fmt.Errorf("resource of %s has invalid characters: %q", key, resource)
+		}
+		shellBindings = append(shellBindings, shellBinding{
+			keys:     append(append([]shellKey{}, prefixKeys...), bindingKeys...),
+			resource: resource,
+			name:     strings.ReplaceAll(key, "-", "_"),
+		})
+	}
+	var completeKeys []shellKey
+	if completeKey != "" {
+		keys, err := parseShellKeys(completeKey)
+		if err != nil {
+			return nil, fmt.Errorf("complete key: %w", err)
+		}
+		if len(keys) != 1 {
+			return nil, fmt.Errorf("complete key: key must be like ctrl-p or alt-p: %q", completeKey)
+		}
+		completeKeys = append(append([]shellKey{}, prefixKeys...), keys...)
+	}
+	return &initCli{
+		shell:        shell,
+		bindings:     shellBindings,
+		completeKeys: completeKeys,
+	}, nil
+}
+
+func (c initCli) Run(ctx context.Context, ioIn io.Reader, ioOut io.Writer, ioErr io.Writer) error {
+	var script string
+	switch c.shell {
+	case ShellBash:
+		script = c.bashScript()
+	case ShellZsh:
+		script = c.zshScript()
+	case ShellFish:
+		script = c.fishScript()
+	}
+	if _, err := io.WriteString(ioOut, script); err != nil {
+		return fmt.Errorf("failed to output the script: %w", err)
+	}
+	return nil
+}
+
+// bashScript binds keys by bind -x, which inserts objects at the cursor of READLINE_LINE
+func (c initCli) bashScript() string {
+	var script strings.Builder
+	script.WriteString(`# Key bindings of kubectl fzf for bash. Add the next line to ~/.bashrc
+#   eval "$(kubectl fzf init bash)"
+__kubectl_fzf_insert() {
+  local output
+  # Nothing is inserted if it's canceled or fails
+  output="$(kubectl fzf "$@")" || return
+  local selected="" line
+  while IFS= read -r line; do
+    [ -n "$line" ] && selected="$selected$(printf '%q' "$line") "
+  done <<< "$output"
+  READLINE_LINE="${READLINE_LINE:0:READLINE_POINT}${selected}${READLINE_LINE:READLINE_POINT}"
+  READLINE_POINT=$((READLINE_POINT + ${#selected}))
+}
+`)
+	for _, binding := range c.bindings {
+		fmt.Fprintf(&script, "bind -x '\"%s\": %s'\n", bashKeys(binding.keys), withShellArgument("__kubectl_fzf_insert", binding.resource))
+	}
+	if len(c.completeKeys) > 0 {
+		script.WriteString(`__kubectl_fzf_complete() {
+  local left="${READLINE_LINE:0:READLINE_POINT}" output
+  output="$(kubectl fzf complete --line "$left")" || return
+  # The output replaces the last word before the cursor
+  left="${left%"${left##* }"}${output}"
+  READLINE_LINE="${left}${READLINE_LINE:READLINE_POINT}"
+  READLINE_POINT=${#left}
+}
+`)
+		fmt.Fprintf(&script, "bind -x '\"%s\": __kubectl_fzf_complete'\n", bashKeys(c.completeKeys))
+	}
+	return script.String()
+}
+
+func bashKeys(keys []shellKey) string {
+	var s strings.Builder
+	for _, key := range keys {
+		if key.modifier == "ctrl" {
+			s.WriteString(`\C-` + key.char)
+			continue
+		}
+		s.WriteString(`\e` + key.char)
+	}
+	return s.String()
+}
+
+// zshScript binds keys to ZLE widgets, which insert objects into LBUFFER
+func (c initCli) zshScript() string {
+	var script strings.Builder
+	script.WriteString(`# Key bindings of kubectl fzf for zsh. Add the next line to ~/.zshrc
+#   eval "$(kubectl fzf init zsh)"
+__kubectl_fzf_insert() {
+  local output
+  # Nothing is inserted if it's canceled or fails
+  if output="$(kubectl fzf "$@")" && [[ -n "$output" ]]; then
+    LBUFFER+="${(j: :)${(@q)${(@f)output}}} "
+  fi
+  zle reset-prompt
+}
+`)
+	for _, binding := range c.bindings {
+		widget := "__kubectl_fzf_widget_" + binding.name
+		fmt.Fprintf(&script, "%s() { %s }\n", widget, withShellArgument("__kubectl_fzf_insert", binding.resource))
+		fmt.Fprintf(&script, "zle -N %s\n", widget)
+		fmt.Fprintf(&script, "bindkey '%s' %s\n", zshKeys(binding.keys), widget)
+	}
+	if len(c.completeKeys) > 0 {
+		script.WriteString(`__kubectl_fzf_complete() {
+  local output
+  if output="$(kubectl fzf complete --line "$LBUFFER")"; then
+    # The output replaces the last word before the cursor
+    LBUFFER="${LBUFFER%"${LBUFFER##* }"}${output}"
+  fi
+  zle reset-prompt
+}
+zle -N __kubectl_fzf_complete
+`)
+		fmt.Fprintf(&script, "bindkey '%s' __kubectl_fzf_complete\n", zshKeys(c.completeKeys))
+	}
+	return script.String()
+}
+
+func zshKeys(keys []shellKey) string {
+	var s strings.Builder
+	for _, key := range keys {
+		if key.modifier == "ctrl" {
+			s.WriteString("^" + strings.ToUpper(key.char))
+			continue
+		}
+		s.WriteString("^[" + key.char)
+	}
+	return s.String()
+}
+
+// fishScript binds keys to the function, which inserts objects by commandline
+func (c initCli) fishScript() string {
+	var script strings.Builder
+	script.WriteString(`# Key bindings of kubectl fzf for fish. Add the next line to ~/.config/fish/config.fish
+#   kubectl fzf init fish | source
+function __kubectl_fzf_insert
+    # Nothing is inserted if it's canceled or fails
+    set -l selected (kubectl fzf $argv)
+    and test -n "$selected"
+    and commandline -i -- (string join ' ' (string escape -- $selected))' '
+    commandline -f repaint
+end
+`)
+	for _, binding := range c.bindings {
+		fmt.Fprintf(&script, "bind %s '%s'\n", fishKeys(binding.keys), withShellArgument("__kubectl_fzf_insert", binding.resource))
+	}
+	if len(c.completeKeys) > 0 {
+		script.WriteString(`function __kubectl_fzf_complete
+    set -l output (kubectl fzf complete --line (commandline -cb) | string collect -N)
+    # The output replaces the current token, which is the last word before the cursor
+    and commandline -rt -- $output
+    commandline -f repaint
+end
+`)
+		fmt.Fprintf(&script, "bind %s __kubectl_fzf_complete\n", fishKeys(c.completeKeys))
+	}
+	return script.String()
+}
+
+func fishKeys(keys []shellKey) string {
+	var s strings.Builder
+	for _, key := range keys {
+		if key.modifier == "ctrl" {
+			s.WriteString(`\c` + key.char)
+			continue
+		}
+		s.WriteString(`\e` + key.char)
+	}
+	return s.String()
+}
+
+// withShellArgument returns the command with the argument if it's not empty
+func withShellArgument(command string, argument string) string {
+	if argument == "" {
+		return command
+	}
+	return command + " " + argument
+}
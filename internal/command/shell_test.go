@@ -0,0 +1,141 @@
+package command
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var updateGolden = flag.Bool("update", false, "update golden files in testdata")
+
+func TestInitCli_Run(t *testing.T) {
+	testCases := []struct {
+		name        string
+		shell       string
+		prefix      string
+		bindings    map[string]string
+		completeKey string
+	}{
+		{
+			name:        "bash",
+			shell:       ShellBash,
+			prefix:      DefaultShellPrefix,
+			bindings:    defaultShellBindings,
+			completeKey: DefaultShellCompleteKey,
+		},
+		{
+			name:        "zsh",
+			shell:       ShellZsh,
+			prefix:      DefaultShellPrefix,
+			bindings:    defaultShellBindings,
+			completeKey: DefaultShellCompleteKey,
+		},
+		{
+			name:        "fish",
+			shell:       ShellFish,
+			prefix:      DefaultShellPrefix,
+			bindings:    defaultShellBindings,
+			completeKey: DefaultShellCompleteKey,
+		},
+		{
+			name:  "bash_custom",
+			shell: ShellBash,
+			bindings: map[string]string{
+				"alt-p": "pods,services",
+				"alt-k": "",
+			},
+			completeKey: "alt-f",
+		},
+		{
+			name:   "zsh_custom",
+			shell:  ShellZsh,
+			prefix: "alt-k",
+			bindings: map[string]string{
+				"ctrl-d": "deployments.apps",
+			},
+		},
+		{
+			name:   "fish_custom",
+			shell:  ShellFish,
+			prefix: "alt-k",
+			bindings: map[string]string{
+				"ctrl-d": "deployments.apps",
+			},
+			completeKey: "ctrl-o",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sut, err := NewInitCli(tc.shell, tc.prefix, tc.bindings, tc.completeKey)
+			require.NoError(t, err)
+			var got bytes.Buffer
+			require.NoError(t, sut.Run(context.Background(), strings.NewReader(""), &got, io.Discard))
+
+			golden := filepath.Join("testdata", "init", tc.name+"."+tc.shell)
+			if *updateGolden {
+				require.NoError(t, os.WriteFile(golden, got.Bytes(), 0644))
+			}
+			want, err := os.ReadFile(golden)
+			require.NoError(t, err)
+			assert.Equal(t, string(want), got.String())
+		})
+	}
+}
+
+func TestNewInitCli(t *testing.T) {
+	testCases := []struct {
+		name        string
+		shell       string
+		prefix      string
+		bindings    map[string]string
+		completeKey string
+		wantErr     error
+	}{
+		{
+			name:    "unknown shell",
+			shell:   "tcsh",
+			wantErr: errors.New("shell must be one of [bash, zsh, fish]"),
+		},
+		{
+			name:    "invalid prefix",
+			shell:   ShellBash,
+			prefix:  "C-x",
+			wantErr: fmt.Errorf("prefix: %w", errors.New(`key must be like ctrl-p or alt-p: "C-x"`)),
+		},
+		{
+			name:     "multiple keys for a binding",
+			shell:    ShellZsh,
+			bindings: map[string]string{"ctrl-x,ctrl-p": "pods"},
+			wantErr:  errors.New(`key must be like ctrl-p or alt-p: "ctrl-x,ctrl-p"`),
+		},
+		{
+			name:     "resource with invalid characters",
+			shell:    ShellFish,
+			bindings: map[string]string{"ctrl-p": "pods; rm -rf"},
+			wantErr:  errors.New(`resource of ctrl-p has invalid characters: "pods; rm -rf"`),
+		},
+		{
+			name:        "multiple keys for the complete key",
+			shell:       ShellBash,
+			completeKey: "ctrl-x,ctrl-f",
+			wantErr:     errors.New(`complete key: key must be like ctrl-p or alt-p: "ctrl-x,ctrl-f"`),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, gotErr := NewInitCli(tc.shell, tc.prefix, tc.bindings, tc.completeKey)
+			assert.Nil(t, got)
+			assert.Equal(t, tc.wantErr, gotErr)
+		})
+	}
+}
@@ -0,0 +1,24 @@
+# Key bindings of kubectl fzf for bash. Add the next line to ~/.bashrc
+#   eval "$(kubectl fzf init bash)"
+__kubectl_fzf_insert() {
+  local output
+  # Nothing is inserted if it's canceled or fails
+  output="$(kubectl fzf "$@")" || return
+  local selected="" line
+  while IFS= read -r line; do
+    [ -n "$line" ] && selected="$selected$(printf '%q' "$line") "
+  done <<< "$output"
+  READLINE_LINE="${READLINE_LINE:0:READLINE_POINT}${selected}${READLINE_LINE:READLINE_POINT}"
+  READLINE_POINT=$((READLINE_POINT + ${#selected}))
+}
+bind -x '"\ek": __kubectl_fzf_insert'
+bind -x '"\ep": __kubectl_fzf_insert pods,services'
+__kubectl_fzf_complete() {
+  local left="${READLINE_LINE:0:READLINE_POINT}" output
+  output="$(kubectl fzf complete --line "$left")" || return
+  # The output replaces the last word before the cursor
+  left="${left%"${left##* }"}${output}"
+  READLINE_LINE="${left}${READLINE_LINE:READLINE_POINT}"
+  READLINE_POINT=${#left}
+}
+bind -x '"\ef": __kubectl_fzf_complete'
@@ -0,0 +1,23 @@
+# Key bindings of kubectl fzf for fish. Add the next line to ~/.config/fish/config.fish
+#   kubectl fzf init fish | source
+function __kubectl_fzf_insert
+    # Nothing is inserted if it's canceled or fails
+    set -l selected (kubectl fzf $argv)
+    and test -n "$selected"
+    and commandline -i -- (string join ' ' (string escape -- $selected))' '
+    commandline -f repaint
+end
+bind \cx\ck\ca '__kubectl_fzf_insert all'
+bind \cx\ck\cc '__kubectl_fzf_insert configmap'
+bind \cx\ck\cd '__kubectl_fzf_insert deployment'
+bind \cx\ck\ch '__kubectl_fzf_insert horizontalpodautoscaler'
+bind \cx\ck\ck '__kubectl_fzf_insert'
+bind \cx\ck\cp '__kubectl_fzf_insert pod'
+bind \cx\ck\cs '__kubectl_fzf_insert service'
+function __kubectl_fzf_complete
+    set -l output (kubectl fzf complete --line (commandline -cb) | string collect -N)
+    # The output replaces the current token, which is the last word before the cursor
+    and commandline -rt -- $output
+    commandline -f repaint
+end
+bind \cx\ck\cf __kubectl_fzf_complete
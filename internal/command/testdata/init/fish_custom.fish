@@ -0,0 +1,17 @@
+# Key bindings of kubectl fzf for fish. Add the next line to ~/.config/fish/config.fish
+#   kubectl fzf init fish | source
+function __kubectl_fzf_insert
+    # Nothing is inserted if it's canceled or fails
+    set -l selected (kubectl fzf $argv)
+    and test -n "$selected"
+    and commandline -i -- (string join ' ' (string escape -- $selected))' '
+    commandline -f repaint
+end
+bind \ek\cd '__kubectl_fzf_insert deployments.apps'
+function __kubectl_fzf_complete
+    set -l output (kubectl fzf complete --line (commandline -cb) | string collect -N)
+    # The output replaces the current token, which is the last word before the cursor
+    and commandline -rt -- $output
+    commandline -f repaint
+end
+bind \ek\co __kubectl_fzf_complete